@@ -6,10 +6,15 @@ import (
 	"api_quiz/internal/handler"
 	"api_quiz/internal/repository"
 	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/mailer"
+	"api_quiz/utils/middleware"
+	"api_quiz/utils/oidc"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/lpernett/godotenv"
 )
@@ -19,27 +24,148 @@ func main() {
 		log.Println("⚠ No .env file found, using system environment variables")
 	}
 
+	keys, err := helper.NewKeyManagerFromEnv()
+	if err != nil {
+		log.Fatalf("failed setup jwt keys %v", err)
+	}
+	helper.SetKeyManager(keys)
+	go keys.StartRotation(time.Hour, nil)
+
+	passwordPolicy, err := helper.NewPasswordPolicyFromEnv()
+	if err != nil {
+		log.Fatalf("failed setup password policy %v", err)
+	}
+
 	database.ConnectDB()
+
+	mail, err := mailer.NewFromEnv()
+	if err != nil {
+		log.Fatalf("failed setup mailer %v", err)
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:" + port
+	}
+
+	frontendURL := os.Getenv("FRONTEND_BASE_URL")
+	if frontendURL == "" {
+		log.Printf("⚠ FRONTEND_BASE_URL is not set, password emails only carry the token")
+	}
+
 	//auth
 	authRepo := repository.NewAuthRepository(database.DB)
-	authUsecase := usecase.NewAuthUseCase(authRepo)
+	tokenRepo := repository.NewTokenRepository(database.DB)
+	sessionRepo := repository.NewSessionRepository(database.DB)
+	revocationRepo := newRevocationRepository()
+	attemptRepo := repository.NewLoginAttemptRepository(database.DB)
+	submissionRepo := repository.NewSubmissionRepository(database.DB)
+	authUsecase := usecase.NewAuthUseCase(authRepo, tokenRepo, sessionRepo, revocationRepo, attemptRepo, submissionRepo, passwordPolicy, mail, baseURL, frontendURL)
 	authHandler := handler.NewAuthHandler(authUsecase)
+	go runPurge(authUsecase)
+
+	sessionUsecase := usecase.NewSessionUseCase(sessionRepo, tokenRepo)
+	sessionHandler := handler.NewSessionHandler(sessionUsecase)
+
+	userUsecase := usecase.NewUserUseCase(authRepo, tokenRepo, revocationRepo, passwordPolicy, mail, baseURL)
+	userHandler := handler.NewUserHandler(userUsecase)
+
+	exportRepo := repository.NewExportRepository(database.DB)
+	exportUsecase := usecase.NewExportUseCase(exportRepo, authRepo, mail, baseURL, exportDir())
+	exportHandler := handler.NewExportHandler(exportUsecase)
+	go runExportCleanup(exportUsecase)
+
+	twoFactorRepo := repository.NewTwoFactorRepository(database.DB)
+	twoFactorUsecase := usecase.NewTwoFactorUseCase(authRepo, twoFactorRepo, tokenRepo, sessionRepo, revocationRepo, submissionRepo, attemptRepo, mail, baseURL, totpIssuer())
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorUsecase)
+
+	apiKeyRepo := repository.NewAPIKeyRepository(database.DB)
+	apiKeyUsecase := usecase.NewAPIKeyUseCase(apiKeyRepo)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyUsecase)
+
+	identityRepo := repository.NewIdentityRepository(database.DB)
+	oauthUsecase := usecase.NewOAuthUseCase(authRepo, tokenRepo, sessionRepo, identityRepo, oidc.ProvidersFromEnv(baseURL))
+	oauthHandler := handler.NewOAuthHandler(oauthUsecase)
+
+	orgRepo := repository.NewOrganizationRepository(database.DB)
+	orgUsecase := usecase.NewOrganizationUseCase(orgRepo, authRepo)
+	orgHandler := handler.NewOrganizationHandler(orgUsecase)
 
+	classRepo := repository.NewClassRepository(database.DB)
 	quizRepo := repository.NewQuizRepository(database.DB)
-	quizUsecase := usecase.NewQuizUseCase(quizRepo)
+	quizUsecase := usecase.NewQuizUseCase(quizRepo, classRepo)
 	quizHandler := handler.NewQuizHandler(quizUsecase)
 
-	submissionRepo := repository.NewSubmissionRepository(database.DB)
 	submissionUseCase := usecase.NewSubmissionUseCase(submissionRepo, quizRepo)
 	submissionHandler := handler.NewSubmissionHandler(submissionUseCase)
 
-	r := route.SetupRoutes(authHandler, quizHandler, submissionHandler)
+	guestUsecase := usecase.NewGuestUseCase(quizRepo, submissionRepo, revocationRepo)
+	guestHandler := handler.NewGuestHandler(guestUsecase)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	classUsecase := usecase.NewClassUseCase(classRepo, quizRepo, submissionRepo, authRepo, orgRepo, baseURL)
+	classHandler := handler.NewClassHandler(classUsecase)
+
+	rosterRepo := repository.NewRosterRepository(database.DB)
+	rosterUsecase := usecase.NewRosterUseCase(rosterRepo, classRepo, mail, baseURL, frontendURL)
+	rosterHandler := handler.NewRosterHandler(rosterUsecase)
+
+	authMiddleware := middleware.NewAuthMiddleware(revocationRepo, apiKeyRepo, sessionRepo, orgRepo)
+
+	jwksHandler := handler.NewJWKSHandler(keys)
+
+	r := route.SetupRoutes(authMiddleware, jwksHandler, authHandler, sessionHandler, userHandler, exportHandler, twoFactorHandler, apiKeyHandler, oauthHandler, guestHandler, orgHandler, classHandler, rosterHandler, quizHandler, submissionHandler)
 
 	fmt.Println("🚀 Server running on http://localhost:" + port)
 	log.Fatal(http.ListenAndServe(":"+port, r))
 }
+
+// REVOCATION_STORE=memory keeps the denylist in process, anything else uses the database
+func newRevocationRepository() repository.RevocationRepository {
+	if os.Getenv("REVOCATION_STORE") == "memory" {
+		return repository.NewMemoryRevocationRepository()
+	}
+	return repository.NewRevocationRepository(database.DB)
+}
+
+// TOTP_ISSUER is the name shown in authenticator apps
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "API Quiz"
+}
+
+// runPurge anonymizes accounts whose restore window is over, once at startup and then daily
+func runPurge(authUsecase usecase.AuthUseCase) {
+	for {
+		purged, err := authUsecase.PurgeDeletedUsers()
+		if err != nil {
+			log.Printf("failed purge deleted users %v", err)
+		} else if purged > 0 {
+			log.Printf("purged %d deleted users", purged)
+		}
+		time.Sleep(24 * time.Hour)
+	}
+}
+
+// EXPORT_DIR is where data export archives are written until their link expires
+func exportDir() string {
+	if dir := os.Getenv("EXPORT_DIR"); dir != "" {
+		return dir
+	}
+	return "exports"
+}
+
+func runExportCleanup(exportUsecase usecase.ExportUseCase) {
+	for {
+		if err := exportUsecase.CleanupExports(); err != nil {
+			log.Printf("failed cleanup exports %v", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
@@ -13,10 +13,18 @@ func main() {
 		log.Fatal("❌ Database belum diinisialisasi")
 	}
 
-	err := database.DB.AutoMigrate(&entity.User{}, &entity.Quiz{}, &entity.Question{}, &entity.Answer{}, &entity.Submission{}, &entity.SubmissionUserAnswer{})
+	err := database.DB.AutoMigrate(&entity.User{}, &entity.Quiz{}, &entity.Question{}, &entity.Answer{}, &entity.Submission{}, &entity.SubmissionUserAnswer{}, &entity.RefreshToken{}, &entity.Session{}, &entity.RevokedToken{}, &entity.UserRevocation{}, &entity.UserToken{},
+		&entity.RecoveryCode{}, &entity.APIKey{}, &entity.FailedLogin{}, &entity.DataExport{},
+		&entity.UserIdentity{}, &entity.OAuthState{}, &entity.Organization{}, &entity.Membership{},
+		&entity.Class{}, &entity.ClassMember{})
 	if err != nil {
 		log.Fatalf("gagal migrasi boy %v", err)
 	}
 
+	// user lama yang sudah punya quiz tetap bisa kelola quiz nya
+	if err := database.DB.Exec("UPDATE users SET role = ? WHERE role = ? AND id IN (SELECT creator_id FROM quizzes WHERE creator_id IS NOT NULL)", entity.RoleCreator, entity.RoleStudent).Error; err != nil {
+		log.Fatalf("gagal migrasi role %v", err)
+	}
+
 	log.Println("berhasil migrasi")
 }
@@ -0,0 +1,179 @@
+// mockidp is a tiny OpenID Connect provider for trying the social login locally.
+// It approves every authorization request for MOCKIDP_EMAIL without asking anything.
+//
+//	MOCKIDP_PORT=9000 go run ./cmd/mockidp
+//	OIDC_PROVIDERS=mock OIDC_MOCK_ISSUER=http://localhost:9000 OIDC_MOCK_CLIENT_ID=quiz go run ./cmd
+package main
+
+import (
+	"api_quiz/utils/helper"
+	"api_quiz/utils/oidc"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "mock-key"
+
+type authorization struct {
+	clientId      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+type server struct {
+	issuer string
+	email  string
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+func main() {
+	port := os.Getenv("MOCKIDP_PORT")
+	if port == "" {
+		port = "9000"
+	}
+	email := os.Getenv("MOCKIDP_EMAIL")
+	if email == "" {
+		email = "student@example.com"
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("failed generate key %v", err)
+	}
+
+	s := &server{
+		issuer: "http://localhost:" + port,
+		email:  email,
+		key:    key,
+		codes:  make(map[string]authorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /token", s.token)
+	mux.HandleFunc("GET /jwks", s.jwks)
+
+	fmt.Println("🧪 Mock IdP running on " + s.issuer + " as " + email)
+	log.Fatal(http.ListenAndServe(":"+port, mux))
+}
+
+func (s *server) discovery(w http.ResponseWriter, r *http.Request) {
+	helper.WriteJSON(w, http.StatusOK, oidc.Metadata{
+		Issuer:                s.issuer,
+		AuthorizationEndpoint: s.issuer + "/authorize",
+		TokenEndpoint:         s.issuer + "/token",
+		JWKSURI:               s.issuer + "/jwks",
+	})
+}
+
+func (s *server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		helper.WriteError(w, http.StatusBadRequest, "only authorization code with S256 pkce is supported")
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Host == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid redirect_uri")
+		return
+	}
+
+	code, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.codes[code] = authorization{
+		clientId:      query.Get("client_id"),
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	s.mu.Unlock()
+
+	callback := redirectURI.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callback.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	auth, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	if !ok || time.Now().After(auth.expiresAt) ||
+		r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != auth.clientId ||
+		r.PostForm.Get("redirect_uri") != auth.redirectURI ||
+		oidc.S256Challenge(r.PostForm.Get("code_verifier")) != auth.codeChallenge {
+		helper.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := oidc.IDTokenClaims{
+		Email:         s.email,
+		EmailVerified: true,
+		Nonce:         auth.nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   "mock|" + s.email,
+			Audience:  jwt.ClaimStrings{auth.clientId},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(s.key)
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, oidc.TokenResponse{
+		AccessToken: "mock-access-token",
+		IDToken:     idToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   300,
+	})
+}
+
+func (s *server) jwks(w http.ResponseWriter, r *http.Request) {
+	key, err := helper.NewJWK(keyID, "RS256", &s.key.PublicKey)
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, helper.JWKS{Keys: []helper.JWK{key}})
+}
@@ -0,0 +1,87 @@
+// roster imports students from a csv file straight into the database, the same way POST /roster/import does.
+// It runs with admin rights, so existing accounts are pulled into the organization too.
+//
+//	go run ./cmd/roster -file students.csv -org smp-1
+package main
+
+import (
+	"api_quiz/cmd/database"
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/mailer"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/lpernett/godotenv"
+)
+
+func main() {
+	file := flag.String("file", "", "csv with email, username and an optional class id or name per row")
+	orgSlug := flag.String("org", "", "slug of the organization to import into, empty for the shared space")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠ No .env file found, using system environment variables")
+	}
+
+	csvFile, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("gagal buka file %v", err)
+	}
+	defer csvFile.Close()
+
+	database.ConnectDB()
+
+	mail, err := mailer.NewFromEnv()
+	if err != nil {
+		log.Fatalf("failed setup mailer %v", err)
+	}
+
+	actor := dto.Actor{Role: entity.RoleAdmin}
+	if *orgSlug != "" {
+		org, err := repository.NewOrganizationRepository(database.DB).GetOrganizationBySlug(*orgSlug)
+		if err != nil {
+			log.Fatalf("gagal cari organization %v", err)
+		}
+		actor.OrgID = org.ID
+	}
+
+	rosterUsecase := usecase.NewRosterUseCase(repository.NewRosterRepository(database.DB), repository.NewClassRepository(database.DB), mail, baseURL(), os.Getenv("FRONTEND_BASE_URL"))
+	report, err := rosterUsecase.ImportRoster(actor, csvFile)
+	if err != nil {
+		log.Fatalf("gagal import roster %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("created %d, existing %d, failed %d", report.Created, report.Existing, report.Failed)
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// the links in the invitations point at the api, same default as cmd/main.go
+func baseURL() string {
+	if url := os.Getenv("APP_BASE_URL"); url != "" {
+		return url
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	return "http://localhost:" + port
+}
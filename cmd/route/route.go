@@ -1,6 +1,7 @@
 package route
 
 import (
+	"api_quiz/entity"
 	"api_quiz/internal/handler"
 	"api_quiz/utils/middleware"
 	"net/http"
@@ -8,49 +9,164 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func SetupRoutes(authHandler *handler.AuthHandler, quizHandler *handler.QuizHandler, submissionHandler *handler.SubmissionHandler) *mux.Router {
+func SetupRoutes(authMiddleware *middleware.AuthMiddleware, jwksHandler *handler.JWKSHandler, authHandler *handler.AuthHandler, sessionHandler *handler.SessionHandler, userHandler *handler.UserHandler, exportHandler *handler.ExportHandler, twoFactorHandler *handler.TwoFactorHandler, apiKeyHandler *handler.APIKeyHandler, oauthHandler *handler.OAuthHandler, guestHandler *handler.GuestHandler, orgHandler *handler.OrganizationHandler, classHandler *handler.ClassHandler, rosterHandler *handler.RosterHandler, quizHandler *handler.QuizHandler, submissionHandler *handler.SubmissionHandler) *mux.Router {
 	r := mux.NewRouter()
 
+	r.HandleFunc("/.well-known/jwks.json", jwksHandler.JWKS).Methods(http.MethodGet)
+
 	r.HandleFunc("/register", authHandler.Register).Methods(http.MethodPost)
 	r.HandleFunc("/login", authHandler.Login).Methods(http.MethodPost)
+	r.HandleFunc("/login/2fa", twoFactorHandler.VerifyLogin).Methods(http.MethodPost)
+	r.HandleFunc("/login/unlock", authHandler.UnlockAccount).Methods(http.MethodGet)
 	r.HandleFunc("/verification", authHandler.Verification).Methods(http.MethodGet)
+	r.HandleFunc("/verification/resend", authHandler.ResendVerification).Methods(http.MethodPost)
+	r.HandleFunc("/token/refresh", authHandler.RefreshToken).Methods(http.MethodPost)
+	r.HandleFunc("/password/forgot", authHandler.ForgotPassword).Methods(http.MethodPost)
+	r.HandleFunc("/password/reset", authHandler.ResetPassword).Methods(http.MethodPost)
+	r.HandleFunc("/email/confirm", userHandler.ConfirmEmailChange).Methods(http.MethodGet)
+	r.HandleFunc("/account/restore", authHandler.RestoreAccount).Methods(http.MethodGet)
+	r.HandleFunc("/export/download", exportHandler.Download).Methods(http.MethodGet)
+
+	//social login
+	r.HandleFunc("/oauth/{provider}/login", oauthHandler.Login).Methods(http.MethodGet)
+	r.HandleFunc("/oauth/{provider}/callback", oauthHandler.Callback).Methods(http.MethodGet)
+
+	//guest
+	r.HandleFunc("/guest/token", guestHandler.IssueToken).Methods(http.MethodPost)
+	r.HandleFunc("/guest/quiz", guestHandler.GetPublicQuizzes).Methods(http.MethodGet)
+	r.HandleFunc("/guest/quiz/{quizid}", guestHandler.GetPublicQuiz).Methods(http.MethodGet)
+
+	guestRoute := r.PathPrefix("/guest").Subrouter()
+	guestRoute.Use(authMiddleware.GuestAuthMiddleware)
+
+	guestRoute.HandleFunc("/submission/{quizid}", guestHandler.CreateSubmission).Methods(http.MethodPost)
+	guestRoute.HandleFunc("/submissions", guestHandler.GetSubmissions).Methods(http.MethodGet)
 
 	userRoute := r.PathPrefix("/user").Subrouter()
-	userRoute.Use(middleware.JWTAuthMiddleware)
+	userRoute.Use(authMiddleware.JWTAuthMiddleware, middleware.RequireSession)
 
 	userRoute.HandleFunc("/delete", authHandler.DeleteUser).Methods(http.MethodDelete)
+	userRoute.HandleFunc("/me", userHandler.GetProfile).Methods(http.MethodGet)
+	userRoute.HandleFunc("/me", userHandler.UpdateProfile).Methods(http.MethodPatch)
+	userRoute.HandleFunc("/password", userHandler.ChangePassword).Methods(http.MethodPut)
+	userRoute.HandleFunc("/email", userHandler.ChangeEmail).Methods(http.MethodPost)
+	userRoute.HandleFunc("/export", exportHandler.RequestExport).Methods(http.MethodPost)
+	userRoute.HandleFunc("/export/{exportid}", exportHandler.GetExport).Methods(http.MethodGet)
+	userRoute.HandleFunc("/logout", authHandler.Logout).Methods(http.MethodPost)
+	userRoute.HandleFunc("/sessions/revoke-all", authHandler.RevokeAllSessions).Methods(http.MethodPost)
+
+	//session
+	userRoute.HandleFunc("/sessions", sessionHandler.GetSessions).Methods(http.MethodGet)
+	userRoute.HandleFunc("/sessions/revoke-others", sessionHandler.RevokeOtherSessions).Methods(http.MethodPost)
+	userRoute.HandleFunc("/sessions/{sessionid}", sessionHandler.RevokeSession).Methods(http.MethodDelete)
+
+	//2fa
+	userRoute.HandleFunc("/2fa/enroll", twoFactorHandler.Enroll).Methods(http.MethodPost)
+	userRoute.HandleFunc("/2fa/confirm", twoFactorHandler.Confirm).Methods(http.MethodPost)
+	userRoute.HandleFunc("/2fa/disable", twoFactorHandler.Disable).Methods(http.MethodPost)
+	userRoute.HandleFunc("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes).Methods(http.MethodPost)
+
+	//guest attempts made before signing up, e.g. with social login
+	userRoute.HandleFunc("/guest/claim", guestHandler.Claim).Methods(http.MethodPost)
+
+	//api key
+	userRoute.HandleFunc("/api-keys", apiKeyHandler.CreateAPIKey).Methods(http.MethodPost)
+	userRoute.HandleFunc("/api-keys", apiKeyHandler.GetAPIKeys).Methods(http.MethodGet)
+	userRoute.HandleFunc("/api-keys/{keyid}", apiKeyHandler.RevokeAPIKey).Methods(http.MethodDelete)
+
+	//organization
+	orgRoute := r.PathPrefix("/org").Subrouter()
+	orgRoute.Use(authMiddleware.JWTAuthMiddleware, middleware.RequireSession)
+
+	orgRoute.HandleFunc("", orgHandler.CreateOrganization).Methods(http.MethodPost)
+	orgRoute.HandleFunc("", orgHandler.GetMyOrganizations).Methods(http.MethodGet)
+	orgRoute.HandleFunc("/switch", orgHandler.SwitchOrganization).Methods(http.MethodPost)
+	orgRoute.HandleFunc("/{orgid}/members", orgHandler.GetMembers).Methods(http.MethodGet)
+	orgRoute.HandleFunc("/{orgid}/members", orgHandler.AddMember).Methods(http.MethodPost)
+	orgRoute.HandleFunc("/{orgid}/members/{userid}", orgHandler.UpdateMember).Methods(http.MethodPut)
+	orgRoute.HandleFunc("/{orgid}/members/{userid}", orgHandler.RemoveMember).Methods(http.MethodDelete)
+
+	//class
+	classRoute := r.PathPrefix("/class").Subrouter()
+	classRoute.Use(authMiddleware.JWTAuthMiddleware, middleware.RequireSession)
+
+	classRoute.HandleFunc("", classHandler.CreateClass).Methods(http.MethodPost)
+	classRoute.HandleFunc("", classHandler.GetMyClasses).Methods(http.MethodGet)
+	classRoute.HandleFunc("/join", classHandler.JoinClass).Methods(http.MethodPost)
+	classRoute.HandleFunc("/{classid}", classHandler.GetClass).Methods(http.MethodGet)
+	classRoute.HandleFunc("/{classid}/join-code", classHandler.RegenerateJoinCode).Methods(http.MethodPost)
+	classRoute.HandleFunc("/{classid}/members", classHandler.GetMembers).Methods(http.MethodGet)
+	classRoute.HandleFunc("/{classid}/members", classHandler.AddMember).Methods(http.MethodPost)
+	classRoute.HandleFunc("/{classid}/members/{userid}", classHandler.RemoveMember).Methods(http.MethodDelete)
+	classRoute.HandleFunc("/{classid}/quizzes", classHandler.GetClassQuizzes).Methods(http.MethodGet)
+	classRoute.HandleFunc("/{classid}/submissions", classHandler.GetClassSubmissions).Methods(http.MethodGet)
+
+	//roster
+	rosterRoute := r.PathPrefix("/roster").Subrouter()
+	rosterRoute.Use(authMiddleware.JWTAuthMiddleware, middleware.RequireSession)
+
+	rosterRoute.HandleFunc("/import", rosterHandler.ImportRoster).Methods(http.MethodPost)
+
+	//admin
+	adminRoute := r.PathPrefix("/admin").Subrouter()
+	adminRoute.Use(authMiddleware.JWTAuthMiddleware, middleware.RequireSession, middleware.RequireRole(entity.RoleAdmin))
+
+	adminRoute.HandleFunc("/user/{userid}/role", authHandler.UpdateRole).Methods(http.MethodPut)
 
 	//quiz
 	quizRoute := r.PathPrefix("/quiz").Subrouter()
-	quizRoute.Use(middleware.JWTAuthMiddleware)
+	quizRoute.Use(authMiddleware.JWTAuthMiddleware)
+
+	quizReadRoute := quizRoute.NewRoute().Subrouter()
+	quizReadRoute.Use(middleware.RequireScope(entity.ScopeQuizRead))
 
 	//quiz
-	quizRoute.HandleFunc("/get", quizHandler.GetAllQuiz).Methods(http.MethodGet)
-	quizRoute.HandleFunc("/get/{quizid}", quizHandler.GetQuizById).Methods(http.MethodGet)
-	quizRoute.HandleFunc("/create", quizHandler.CreateQuiz).Methods(http.MethodPost)
-	quizRoute.HandleFunc("/update/{quizid}", quizHandler.CreateQuiz).Methods(http.MethodPut)
-	quizRoute.HandleFunc("/delete/{quizid}", quizHandler.DeleteQuiz).Methods(http.MethodDelete)
+	quizReadRoute.HandleFunc("/get", quizHandler.GetAllQuiz).Methods(http.MethodGet)
+	quizReadRoute.HandleFunc("/get/{quizid}", quizHandler.GetQuizById).Methods(http.MethodGet)
 	//question
-	quizRoute.HandleFunc("/{quizid}/get/question", quizHandler.GetQuestionAnswerByQuizId).Methods(http.MethodGet)
-	quizRoute.HandleFunc("/{quizid}/get/question/{questionid}", quizHandler.GetQuestionById).Methods(http.MethodGet)
-	quizRoute.HandleFunc("/{quizid}/question/create", quizHandler.CreateQuestionAndAnswer).Methods(http.MethodPost)
-	quizRoute.HandleFunc("/{quizid}/question/{questionid}/delete", quizHandler.DeleteQuestion).Methods(http.MethodDelete)
-	quizRoute.HandleFunc("/{quizid}/question/{questionid}/update", quizHandler.UpdateQuestion).Methods(http.MethodPut)
+	quizReadRoute.HandleFunc("/{quizid}/get/question", quizHandler.GetQuestionAnswerByQuizId).Methods(http.MethodGet)
+	quizReadRoute.HandleFunc("/{quizid}/get/question/{questionid}", quizHandler.GetQuestionById).Methods(http.MethodGet)
 	//answer
-	quizRoute.HandleFunc("/get/question{questionid}", quizHandler.GetAnswerByQuestionId).Methods(http.MethodGet)
-	quizRoute.HandleFunc("/{quizid}/question/{questionid}/answer/{answerid}/update", quizHandler.UpdateAnswer).Methods(http.MethodPut)
-	quizRoute.HandleFunc("/{quizid}/question/{questionid}/answer/add", quizHandler.AddAnswer).Methods(http.MethodPost)
-	quizRoute.HandleFunc("/{quizid}/question/{questionid}/answer/{answerid}/delete", quizHandler.DeleteAnswer).Methods(http.MethodDelete)
+	quizReadRoute.HandleFunc("/get/question{questionid}", quizHandler.GetAnswerByQuestionId).Methods(http.MethodGet)
+
+	//quiz management, only creators and admins
+	quizManageRoute := quizRoute.NewRoute().Subrouter()
+	quizManageRoute.Use(middleware.RequireRole(entity.RoleAdmin, entity.RoleCreator), middleware.RequireScope(entity.ScopeQuizWrite))
+
+	quizManageRoute.HandleFunc("/create", quizHandler.CreateQuiz).Methods(http.MethodPost)
+	quizManageRoute.HandleFunc("/update/{quizid}", quizHandler.UpdateQuiz).Methods(http.MethodPut)
+	quizManageRoute.HandleFunc("/delete/{quizid}", quizHandler.DeleteQuiz).Methods(http.MethodDelete)
+	quizManageRoute.HandleFunc("/{quizid}/question/create", quizHandler.CreateQuestionAndAnswer).Methods(http.MethodPost)
+	quizManageRoute.HandleFunc("/{quizid}/question/{questionid}/delete", quizHandler.DeleteQuestion).Methods(http.MethodDelete)
+	quizManageRoute.HandleFunc("/{quizid}/question/{questionid}/update", quizHandler.UpdateQuestion).Methods(http.MethodPut)
+	quizManageRoute.HandleFunc("/{quizid}/questions/order", quizHandler.ReorderQuestions).Methods(http.MethodPut)
+	quizManageRoute.HandleFunc("/{quizid}/question/{questionid}/answer/{answerid}/update", quizHandler.UpdateAnswer).Methods(http.MethodPut)
+	quizManageRoute.HandleFunc("/{quizid}/question/{questionid}/answer/add", quizHandler.AddAnswer).Methods(http.MethodPost)
+	quizManageRoute.HandleFunc("/{quizid}/question/{questionid}/answer/{answerid}/delete", quizHandler.DeleteAnswer).Methods(http.MethodDelete)
+	quizManageRoute.HandleFunc("/{quizid}/question/{questionid}/answers/order", quizHandler.ReorderAnswers).Methods(http.MethodPut)
+
+	quizSubmissionRoute := quizRoute.NewRoute().Subrouter()
+	quizSubmissionRoute.Use(middleware.RequireRole(entity.RoleAdmin, entity.RoleCreator), middleware.RequireScope(entity.ScopeSubmissionRead))
+
+	quizSubmissionRoute.HandleFunc("/{quizid}/submissions", submissionHandler.GetSubmissionByQuizId).Methods(http.MethodGet)
 
 	//submission
 	submissionRoute := r.PathPrefix("/submission").Subrouter()
-	submissionRoute.Use(middleware.JWTAuthMiddleware)
+	submissionRoute.Use(authMiddleware.JWTAuthMiddleware)
+
+	submissionReadRoute := submissionRoute.NewRoute().Subrouter()
+	submissionReadRoute.Use(middleware.RequireScope(entity.ScopeSubmissionRead))
+
+	submissionReadRoute.HandleFunc("/get", submissionHandler.GetAllSubmission).Methods(http.MethodGet)
+	submissionReadRoute.HandleFunc("/mine", submissionHandler.GetMySubmission).Methods(http.MethodGet)
+	submissionReadRoute.HandleFunc("/get/{submissionid}", submissionHandler.GetSubmissionById).Methods(http.MethodGet)
+
+	submissionWriteRoute := submissionRoute.NewRoute().Subrouter()
+	submissionWriteRoute.Use(middleware.RequireScope(entity.ScopeSubmissionWrite))
 
-	submissionRoute.HandleFunc("/get", submissionHandler.GetAllSubmission).Methods(http.MethodGet)
-	submissionRoute.HandleFunc("/get/{submissionid}", submissionHandler.GetSubmissionById).Methods(http.MethodGet)
-	submissionRoute.HandleFunc("/create/{quizid}", submissionHandler.CreateSubmission).Methods(http.MethodPost)
-	submissionRoute.HandleFunc("/update/{submissionid}", submissionHandler.UpdateSubmission).Methods(http.MethodPut)
-	submissionRoute.HandleFunc("/delete/{submissionid}", submissionHandler.DeleteSubmission).Methods(http.MethodDelete)
+	submissionWriteRoute.HandleFunc("/create/{quizid}", submissionHandler.CreateSubmission).Methods(http.MethodPost)
+	submissionWriteRoute.HandleFunc("/update/{submissionid}", submissionHandler.UpdateSubmission).Methods(http.MethodPut)
+	submissionWriteRoute.HandleFunc("/delete/{submissionid}", submissionHandler.DeleteSubmission).Methods(http.MethodDelete)
 
 	return r
 
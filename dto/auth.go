@@ -1,11 +1,158 @@
 package dto
 
+import "time"
+
 type Register struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// always student on self registration, admins promote through the update role endpoint
+	Role string `json:"-"`
+	// optional, attempts made with this guest token move to the new account
+	GuestToken string `json:"guest_token,omitempty"`
+}
+
+// ClientInfo is filled by the handler from the request, it describes the device of a session.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
 }
+
 type Login struct {
-	Email    string `json:"email"`
+	Email      string     `json:"email"`
+	Password   string     `json:"password"`
+	GuestToken string     `json:"guest_token,omitempty"`
+	Client     ClientInfo `json:"-"`
+}
+
+type RefreshToken struct {
+	RefreshToken string     `json:"refresh_token"`
+	Client       ClientInfo `json:"-"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"token_jwt"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type ResendVerification struct {
+	Email string `json:"email"`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// Actor is the authenticated caller the usecases authorize against.
+type Actor struct {
+	UserID  uint
+	Role    string
+	OrgID   uint
+	OrgRole string
+}
+
+type UpdateRole struct {
+	Role string `json:"role"`
+}
+
+// LoginResponse carries the tokens, or only a challenge when the account has 2fa enabled.
+type LoginResponse struct {
+	*TokenResponse
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type TwoFactorLogin struct {
+	ChallengeToken string     `json:"challenge_token"`
+	Code           string     `json:"code"`
+	GuestToken     string     `json:"guest_token,omitempty"`
+	Client         ClientInfo `json:"-"`
+}
+
+type TwoFactorCode struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type CreateAPIKey struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// nil when the key works outside any organization
+	OrganizationID *uint `json:"organization_id"`
+}
+
+// CreatedAPIKeyResponse is the only time the full key is shown.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type ProfileResponse struct {
+	ID               uint      `json:"id"`
+	Username         string    `json:"username"`
+	Email            string    `json:"email"`
+	PendingEmail     string    `json:"pending_email,omitempty"`
+	DisplayName      string    `json:"display_name"`
+	AvatarURL        string    `json:"avatar_url"`
+	Locale           string    `json:"locale"`
+	TimeZone         string    `json:"time_zone"`
+	Role             string    `json:"role"`
+	IsVerified       bool      `json:"is_verified"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// UpdateProfile is a patch, nil fields are left as they are and empty strings clear optional ones.
+type UpdateProfile struct {
+	Username    *string `json:"username"`
+	DisplayName *string `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+	Locale      *string `json:"locale"`
+	TimeZone    *string `json:"time_zone"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ChangeEmail struct {
+	NewEmail string `json:"new_email"`
 	Password string `json:"password"`
 }
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
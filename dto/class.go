@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type CreateClass struct {
+	Name string `json:"name"`
+}
+
+type JoinClass struct {
+	Code string `json:"code"`
+}
+
+type AddClassMember struct {
+	Email string `json:"email"`
+}
+
+// ClassResponse only carries the join code for whoever manages the class.
+type ClassResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	TeacherID uint      `json:"teacher_id"`
+	Role      string    `json:"role,omitempty"`
+	JoinCode  string    `json:"join_code,omitempty"`
+	JoinURL   string    `json:"join_url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ClassMemberResponse struct {
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Email       string    `json:"email"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
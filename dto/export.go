@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+type ExportResponse struct {
+	ID          uint       `json:"id"`
+	Status      string     `json:"status"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+type ExportSubmission struct {
+	SubmissionResponse
+	QuizTitle    string `json:"quiz_title"`
+	RevealPolicy string `json:"-"`
+	QuizCreator  *uint  `json:"-"`
+}
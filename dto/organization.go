@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+type CreateOrganization struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type OrganizationResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AddMember struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type UpdateMember struct {
+	Role string `json:"role"`
+}
+
+type MemberResponse struct {
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// SwitchOrganization with organization_id 0 goes back to the shared space outside any organization.
+type SwitchOrganization struct {
+	OrganizationID uint `json:"organization_id"`
+}
@@ -1,65 +1,126 @@
 package dto
 
 type Quiz struct {
-	Creator uint   `json:"-"`
-	Title   string `json:"title"`
+	Creator          uint   `json:"-"`
+	OrgID            uint   `json:"-"`
+	Title            string `json:"title"`
+	RevealPolicy     string `json:"reveal_policy"`
+	IsPublic         bool   `json:"is_public"`
+	ClassID          *uint  `json:"class_id"`
+	UnansweredPolicy string `json:"unanswered_policy"`
 }
 
+// UpdatedQuiz with class_id 0 lifts the class restriction.
 type UpdatedQuiz struct {
-	ID    uint   `json:"-"`
-	Title string `json:"title"`
+	ID               uint   `json:"-"`
+	Title            string `json:"title"`
+	RevealPolicy     string `json:"reveal_policy"`
+	IsPublic         *bool  `json:"is_public"`
+	ClassID          *uint  `json:"class_id"`
+	UnansweredPolicy string `json:"unanswered_policy"`
 }
 
 type JustQuizResponse struct {
-	ID      uint   `json:"id"`
-	Creator *uint  `json:"creator"`
-	Title   string `json:"title"`
+	ID               uint   `json:"id"`
+	Creator          *uint  `json:"creator"`
+	Title            string `json:"title"`
+	RevealPolicy     string `json:"reveal_policy"`
+	IsPublic         bool   `json:"is_public"`
+	Organization     *uint  `json:"organization_id"`
+	Class            *uint  `json:"class_id"`
+	UnansweredPolicy string `json:"unanswered_policy"`
 }
 
 type QuizResponseWithQS struct {
-	ID       uint               `json:"id"`
-	Creator  uint               `json:"creator"`
-	Title    string             `json:"title"`
-	Question []QuestionResponse `json:"question"`
+	ID               uint               `json:"id"`
+	Creator          *uint              `json:"creator"`
+	Title            string             `json:"title"`
+	RevealPolicy     string             `json:"reveal_policy"`
+	IsPublic         bool               `json:"is_public"`
+	Organization     *uint              `json:"organization_id"`
+	Class            *uint              `json:"class_id"`
+	UnansweredPolicy string             `json:"unanswered_policy"`
+	Question         []QuestionResponse `json:"question"`
 }
 
-// question
+// question, type defaults to single_choice and points to 1
 type Question struct {
-	ID      uint     `json:"-"`
-	QuizID  uint     `json:"-"`
-	Text    string   `json:"text"`
-	Answers []Answer `json:"answer"`
+	ID        uint     `json:"-"`
+	QuizID    uint     `json:"-"`
+	Text      string   `json:"text"`
+	Type      string   `json:"type"`
+	MatchMode string   `json:"match_mode"`
+	Tolerance float64  `json:"tolerance"`
+	Points    float64  `json:"points"`
+	Penalty   float64  `json:"penalty"`
+	Answers   []Answer `json:"answer"`
 }
 
+// QuestionUpdate can not change the type, the answers would lose their meaning, left out fields are kept.
 type QuestionUpdate struct {
-	QuizID uint   `json:"-"`
-	ID     uint   `json:"-"`
-	Text   string `json:"text"`
+	QuizID    uint     `json:"-"`
+	ID        uint     `json:"-"`
+	Text      string   `json:"text"`
+	MatchMode string   `json:"match_mode"`
+	Tolerance *float64 `json:"tolerance"`
+	Points    *float64 `json:"points"`
+	Penalty   *float64 `json:"penalty"`
 }
 
 type JustQuestionResponse struct {
-	ID     uint   `json:"id"`
-	QuizID uint   `json:"quiz_id"`
-	Text   string `json:"text"`
+	ID        uint    `json:"id"`
+	QuizID    uint    `json:"quiz_id"`
+	Position  int     `json:"position"`
+	Text      string  `json:"text"`
+	Type      string  `json:"type"`
+	MatchMode string  `json:"match_mode,omitempty"`
+	Tolerance float64 `json:"tolerance,omitempty"`
+	Points    float64 `json:"points"`
+	Penalty   float64 `json:"penalty,omitempty"`
 }
+
+// QuestionResponse lists the right hand items of a matching question in MatchOptions, sorted, for the player view.
 type QuestionResponse struct {
-	ID     uint             `json:"ID"`
-	QuizID uint             `json:"quiz_id"`
-	Text   string           `json:"text"`
-	Answer []AnswerResponse `json:"answer"`
+	ID           uint             `json:"ID"`
+	QuizID       uint             `json:"quiz_id"`
+	Position     int              `json:"position"`
+	Text         string           `json:"text"`
+	Type         string           `json:"type"`
+	MatchMode    string           `json:"match_mode,omitempty"`
+	Tolerance    float64          `json:"tolerance,omitempty"`
+	Points       float64          `json:"points"`
+	Penalty      float64          `json:"penalty,omitempty"`
+	MatchOptions []string         `json:"match_options,omitempty"`
+	Answer       []AnswerResponse `json:"answer"`
 }
 
-// answer
+// answer, rank is only used by ordering and match by matching questions
 type Answer struct {
 	ID         uint   `json:"-"`
 	QuestionID uint   `json:"-"`
 	Text       string `json:"text"`
 	IsCorrect  bool   `json:"is_correct"`
+	Rank       int    `json:"rank"`
+	MatchText  string `json:"match"`
 }
 
+// AnswerResponse leaves the key fields nil in the player view so they are not serialized.
 type AnswerResponse struct {
-	ID         uint   `json:"id"`
-	QuestionID uint   `json:"question_id"`
-	Text       string `json:"text"`
-	IsCorrect  bool   `json:"is_correct"`
+	ID         uint    `json:"id"`
+	QuestionID uint    `json:"question_id"`
+	Position   int     `json:"position"`
+	Text       string  `json:"text"`
+	IsCorrect  *bool   `json:"is_correct,omitempty"`
+	Rank       *int    `json:"rank,omitempty"`
+	MatchText  *string `json:"match,omitempty"`
+}
+
+// ReorderQuestions lists every question id of the quiz once, in the new order.
+type ReorderQuestions struct {
+	QuestionIDs []uint `json:"question_ids"`
+}
+
+// ReorderAnswers lists every answer id of the question once, in the new order.
+type ReorderAnswers struct {
+	AnswerIDs []uint `json:"answer_ids"`
 }
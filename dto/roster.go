@@ -0,0 +1,20 @@
+package dto
+
+// RosterRowResult is the outcome of one csv row, row numbers count the header line too.
+type RosterRowResult struct {
+	Row      int    `json:"row"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Class    string `json:"class,omitempty"`
+	Status   string `json:"status"`
+	UserID   uint   `json:"user_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type RosterImportReport struct {
+	Created  int               `json:"created"`
+	Existing int               `json:"existing"`
+	Invited  int               `json:"invited"`
+	Failed   int               `json:"failed"`
+	Rows     []RosterRowResult `json:"rows"`
+}
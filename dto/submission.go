@@ -5,23 +5,41 @@ import "time"
 type Submission struct {
 	QuizID  uint               `json:"quiz_id"`
 	UserID  uint               `json:"-"`
+	OrgID   uint               `json:"-"`
+	GuestID string             `json:"-"`
 	Answers []SubmissionAnswer `json:"answers"`
 }
 
+// SubmissionAnswer uses answer_id for single choice and true false, answer_ids for multi select and
+// ordering (in order), text for short text and numeric and pairs for matching questions.
 type SubmissionAnswer struct {
-	QuestionID uint `json:"question_id"`
-	AnswerID   uint `json:"answer_id"`
+	QuestionID uint        `json:"question_id"`
+	AnswerID   uint        `json:"answer_id,omitempty"`
+	AnswerIDs  []uint      `json:"answer_ids,omitempty"`
+	Text       string      `json:"text,omitempty"`
+	Pairs      []MatchPair `json:"pairs,omitempty"`
 }
 
+type MatchPair struct {
+	AnswerID uint   `json:"answer_id"`
+	Match    string `json:"match"`
+}
+
+// SubmissionUpdate overrides the grade with raw points or a percentage, the other one is recomputed, points win when both are sent.
 type SubmissionUpdate struct {
 	SubmissionID uint      `json:"-"`
-	Score        float32   `json:"score"`
+	Points       *float64  `json:"points"`
+	Score        *float32  `json:"score"`
 	UpdatedAt    time.Time `json:"-"`
 }
+
+// Score is the percentage of MaxPoints, Points is the raw total and can be negative with negative marking.
 type JustSubmissionResponse struct {
 	ID        uint      `json:"id"`
 	QuizID    uint      `json:"quiz_id"`
 	UserID    uint      `json:"user_id"`
+	Points    float64   `json:"points"`
+	MaxPoints float64   `json:"max_points"`
 	Score     float32   `json:"score"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -31,6 +49,8 @@ type SubmissionResponse struct {
 	ID        uint                       `json:"id"`
 	QuizID    uint                       `json:"quiz_id"`
 	UserID    uint                       `json:"user_id"`
+	Points    float64                    `json:"points"`
+	MaxPoints float64                    `json:"max_points"`
 	Score     float32                    `json:"score"`
 	CreatedAt time.Time                  `json:"created_at"`
 	UpdatedAt time.Time                  `json:"updated_at"`
@@ -38,8 +58,27 @@ type SubmissionResponse struct {
 }
 
 type SubmissionAnswerResponse struct {
-	QuestionID    uint `json:"question_id"`
-	CorrectAnswer uint `json:"correct_id"`
-	AnswerUser    uint `json:"answer_id"`
-	IsCorrect     bool `json:"is_correct"`
+	QuestionID     uint        `json:"question_id"`
+	CorrectAnswer  *uint       `json:"correct_id,omitempty"`
+	CorrectAnswers []uint      `json:"correct_ids,omitempty"`
+	AnswerUser     uint        `json:"answer_id"`
+	AnswerIDs      []uint      `json:"answer_ids,omitempty"`
+	Text           string      `json:"text,omitempty"`
+	Pairs          []MatchPair `json:"pairs,omitempty"`
+	Skipped        bool        `json:"skipped,omitempty"`
+	IsCorrect      *bool       `json:"is_correct,omitempty"`
+	Points         *float64    `json:"points,omitempty"`
+}
+
+type GuestTokenResponse struct {
+	GuestToken string `json:"guest_token"`
+	ExpiresIn  int64  `json:"expires_in"`
+}
+
+type ClaimGuest struct {
+	GuestToken string `json:"guest_token"`
+}
+
+type ClaimGuestResponse struct {
+	Claimed int64 `json:"claimed"`
 }
@@ -2,6 +2,8 @@ package entity
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
@@ -9,39 +11,125 @@ type User struct {
 	Email      string `gorm:"not null;uniqueIndex;size:255"`
 	Password   string `gorm:"not null"`
 	Username   string `gorm:"not null;uniqueIndex;size:50"`
+	Role       string `gorm:"not null;default:student;size:20"`
 	IsVerified bool   `gorm:"default:false"`
 	CreatedAt  time.Time
+
+	//profile
+	DisplayName  string `gorm:"size:100"`
+	AvatarURL    string `gorm:"size:512"`
+	Locale       string `gorm:"size:16"`
+	TimeZone     string `gorm:"size:64"`
+	PendingEmail string `gorm:"size:255"`
+
+	// totp 2fa, the secret is stored on enroll and only enforced once confirmed
+	TOTPSecret   string `gorm:"size:64"`
+	TOTPEnabled  bool   `gorm:"not null;default:false"`
+	TOTPLastStep int64  `gorm:"not null;default:0"`
+
+	// deleted accounts can be restored until they are anonymized by the purge job
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	AnonymizedAt *time.Time     `gorm:"null"`
+
+	// organization new sessions start in, nil is the shared space outside any organization
+	ActiveOrganizationID *uint `gorm:"null"`
 }
 
+const (
+	RoleAdmin   = "admin"
+	RoleCreator = "creator"
+	RoleStudent = "student"
+)
+
 type Quiz struct {
-	ID        uint       `gorm:"primaryKey"`
-	Title     string     `gorm:"not null"`
-	CreatorID *uint      `gorm:"null:index"`
+	ID           uint   `gorm:"primaryKey"`
+	Title        string `gorm:"not null"`
+	CreatorID    *uint  `gorm:"null:index"`
+	RevealPolicy string `gorm:"not null;default:after_submit;size:20"`
+	IsPublic     bool   `gorm:"not null;default:false"`
+	// whether a submission may skip questions and what a skipped question is worth
+	UnansweredPolicy string `gorm:"not null;default:required;size:20"`
+	// nil for quizzes made outside any organization
+	OrganizationID *uint `gorm:"null;index"`
+	// only the class teacher and its students can see and answer a quiz restricted to a class
+	ClassID   *uint      `gorm:"null;index"`
 	Questions []Question `gorm:"foreignKey:QuizID;constraint:OnDelete:CASCADE;"`
 	CreatedAt time.Time  `gorm:"not null;autoCreateTime"`
 	User      User       `gorm:"foreignKey:CreatorID;constraint:OnDelete:SET NULL;"`
 }
 
+// reveal policy decides what a taker sees about correctness after finishing an attempt
+const (
+	RevealAfterSubmit     = "after_submit"
+	RevealCorrectnessOnly = "correctness_only"
+	RevealNever           = "never"
+)
+
+// unanswered policy, required rejects a submission that skips a question, zero scores a skipped question 0
+// and penalty scores it like a wrong answer
+const (
+	UnansweredRequired = "required"
+	UnansweredZero     = "zero"
+	UnansweredPenalty  = "penalty"
+)
+
 type Question struct {
-	ID      uint     `gorm:"primaryKey"`
-	QuizID  uint     `gorm:"not null;index"`
-	Quiz    Quiz     `gorm:"foreignKey:QuizID"`
-	Text    string   `gorm:"not null"`
+	ID     uint   `gorm:"primaryKey"`
+	QuizID uint   `gorm:"not null;index"`
+	Quiz   Quiz   `gorm:"foreignKey:QuizID"`
+	Text   string `gorm:"not null"`
+	Type   string `gorm:"not null;default:single_choice;size:20"`
+	// place in the quiz, rows made before positions existed keep 0 and fall back to id order
+	Position int `gorm:"not null;default:0"`
+	// how a short text reply is compared with the accepted answers
+	MatchMode string `gorm:"not null;default:exact;size:20"`
+	// how far a numeric reply may be from the answer and still count
+	Tolerance float64 `gorm:"not null;default:0"`
+	Points    float64 `gorm:"not null;default:1"`
+	// subtracted for a wrong answer, 0 turns negative marking off
+	Penalty float64  `gorm:"not null;default:0"`
 	Answers []Answer `gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE;"`
 }
 
+// question types, the answers of a question mean something different for each of them
+const (
+	QuestionSingleChoice = "single_choice"
+	QuestionMultiSelect  = "multi_select"
+	QuestionTrueFalse    = "true_false"
+	QuestionShortText    = "short_text"
+	QuestionNumeric      = "numeric"
+	QuestionOrdering     = "ordering"
+	QuestionMatching     = "matching"
+)
+
+const (
+	MatchExact           = "exact"
+	MatchCaseInsensitive = "case_insensitive"
+	MatchRegex           = "regex"
+)
+
 type Answer struct {
 	ID         uint     `gorm:"primaryKey"`
 	QuestionID uint     `gorm:"not null;index"`
 	Question   Question `gorm:"foreignKey:QuestionID"`
 	Text       string   `gorm:"not null"`
 	IsCorrect  bool     `gorm:"not null"`
+	Position   int      `gorm:"not null;default:0"`
+	// ordering only, place of the item in the correct sequence
+	CorrectRank int `gorm:"not null;default:0"`
+	// matching only, the item this answer has to be paired with
+	MatchText string `gorm:"not null;default:'';size:255"`
 }
 
 type Submission struct {
-	ID        uint  `gorm:"primaryKey"`
-	QuizID    uint  `gorm:"index"`
-	UserID    *uint `gorm:"null;index"`
+	ID     uint  `gorm:"primaryKey"`
+	QuizID uint  `gorm:"index"`
+	UserID *uint `gorm:"null;index"`
+	// set instead of UserID for anonymous attempts until the guest signs up and claims them
+	GuestID *string `gorm:"null;index;size:64"`
+	// raw points can go below 0 with negative marking, score is the percentage of max points and stops at 0
+	Points    float64 `gorm:"not null;default:0"`
+	MaxPoints float64 `gorm:"not null;default:0"`
 	Score     float32
 	CreatedAt time.Time              `gorm:"not null;autoCreateTime"`
 	UpdatedAt time.Time              `gorm:"not null;autoUpdateTime"`
@@ -57,4 +145,191 @@ type SubmissionUserAnswer struct {
 	UserAnswerID uint `gorm:"not null"`
 	CorrectID    uint `gorm:"not null"`
 	IsCorrect    bool `gorm:"not null"`
+	// json of the reply for questions not answered with a single answer id
+	Response string `gorm:"type:text"`
+	// json list of the expected answer ids for multi select and ordering
+	CorrectIDs string  `gorm:"type:text"`
+	Points     float64 `gorm:"not null;default:0"`
+	Skipped    bool    `gorm:"not null;default:false"`
+}
+
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`
+	FamilyID  string     `gorm:"not null;index;size:64"`
+	TokenHash string     `gorm:"not null;uniqueIndex;size:64"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	RevokedAt *time.Time `gorm:"null"`
+	CreatedAt time.Time  `gorm:"not null;autoCreateTime"`
+	User      User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+// Session is one login on one device, it lives as long as its refresh token family.
+type Session struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"not null;index"`
+	FamilyID   string     `gorm:"not null;uniqueIndex;size:64"`
+	UserAgent  string     `gorm:"size:255"`
+	IP         string     `gorm:"size:64"`
+	CreatedAt  time.Time  `gorm:"not null;autoCreateTime"`
+	LastSeenAt time.Time  `gorm:"not null"`
+	RevokedAt  *time.Time `gorm:"null"`
+	// active organization of this session, switching only affects the session it was done in
+	OrganizationID *uint `gorm:"null"`
+	User           User  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+type UserRevocation struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	RevokedAt time.Time `gorm:"not null;precision:6"`
+}
+
+type UserToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`
+	Purpose   string     `gorm:"not null;index;size:32"`
+	TokenHash string     `gorm:"not null;uniqueIndex;size:64"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time `gorm:"null"`
+	CreatedAt time.Time  `gorm:"not null;autoCreateTime"`
+	User      User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
 }
+
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeVerification  = "verification"
+	TokenPurposeUnlock        = "account_unlock"
+	TokenPurposeEmailChange   = "email_change"
+	TokenPurposeRestore       = "account_restore"
+)
+
+// FailedLogin is keyed by the submitted email, not the user, so unknown emails are throttled the same way.
+type FailedLogin struct {
+	ID        uint      `gorm:"primaryKey"`
+	Email     string    `gorm:"not null;index;size:255"`
+	IP        string    `gorm:"not null;index;size:64"`
+	CreatedAt time.Time `gorm:"not null;index;autoCreateTime"`
+}
+
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`
+	CodeHash  string     `gorm:"not null;uniqueIndex;size:64"`
+	UsedAt    *time.Time `gorm:"null"`
+	CreatedAt time.Time  `gorm:"not null;autoCreateTime"`
+	User      User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+// APIKey lets scripts call the api as the user, only the hash of the key is stored.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey"`
+	UserID     uint       `gorm:"not null;index"`
+	Name       string     `gorm:"not null;size:100"`
+	Prefix     string     `gorm:"not null;uniqueIndex;size:16"`
+	KeyHash    string     `gorm:"not null;uniqueIndex;size:64"`
+	Scopes     string     `gorm:"size:255"`
+	ExpiresAt  *time.Time `gorm:"null"`
+	LastUsedAt *time.Time `gorm:"null"`
+	RevokedAt  *time.Time `gorm:"null"`
+	CreatedAt  time.Time  `gorm:"not null;autoCreateTime"`
+	// a key acts in the organization that was active when it was created
+	OrganizationID *uint `gorm:"null"`
+	User           User  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+// an api key without scopes can do everything the role of its owner allows
+const (
+	ScopeQuizRead        = "quiz:read"
+	ScopeQuizWrite       = "quiz:write"
+	ScopeSubmissionRead  = "submission:read"
+	ScopeSubmissionWrite = "submission:write"
+)
+
+// DataExport is a takeout archive of everything the user owns, built in the background.
+type DataExport struct {
+	ID          uint       `gorm:"primaryKey"`
+	UserID      uint       `gorm:"not null;index"`
+	Status      string     `gorm:"not null;default:pending;size:20"`
+	TokenHash   string     `gorm:"not null;uniqueIndex;size:64"`
+	FilePath    string     `gorm:"size:255"`
+	ExpiresAt   *time.Time `gorm:"null"`
+	CompletedAt *time.Time `gorm:"null"`
+	CreatedAt   time.Time  `gorm:"not null;autoCreateTime"`
+	User        User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+const (
+	ExportPending = "pending"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+	ExportExpired = "expired"
+)
+
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	Provider  string    `gorm:"not null;size:50;uniqueIndex:idx_provider_subject"`
+	Subject   string    `gorm:"not null;size:255;uniqueIndex:idx_provider_subject"`
+	Email     string    `gorm:"size:255"`
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+type OAuthState struct {
+	ID           uint      `gorm:"primaryKey"`
+	StateHash    string    `gorm:"not null;uniqueIndex;size:64"`
+	Provider     string    `gorm:"not null;size:50"`
+	CodeVerifier string    `gorm:"not null;size:128"`
+	Nonce        string    `gorm:"not null;size:128"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
+
+// Organization is a workspace like a school, quizzes and their submissions belong to one.
+type Organization struct {
+	ID        uint      `gorm:"primaryKey"`
+	Name      string    `gorm:"not null;size:100"`
+	Slug      string    `gorm:"not null;uniqueIndex;size:50"`
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+}
+
+type Membership struct {
+	ID             uint         `gorm:"primaryKey"`
+	OrganizationID uint         `gorm:"not null;uniqueIndex:idx_membership_org_user"`
+	UserID         uint         `gorm:"not null;uniqueIndex:idx_membership_org_user;index"`
+	Role           string       `gorm:"not null;default:member;size:20"`
+	CreatedAt      time.Time    `gorm:"not null;autoCreateTime"`
+	Organization   Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE;"`
+	User           User         `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+// Class groups students under a teacher, students join it with the join code.
+type Class struct {
+	ID             uint      `gorm:"primaryKey"`
+	OrganizationID *uint     `gorm:"null;index"`
+	TeacherID      uint      `gorm:"not null;index"`
+	Name           string    `gorm:"not null;size:100"`
+	JoinCode       string    `gorm:"not null;uniqueIndex;size:16"`
+	CreatedAt      time.Time `gorm:"not null;autoCreateTime"`
+	Teacher        User      `gorm:"foreignKey:TeacherID;constraint:OnDelete:CASCADE;"`
+}
+
+type ClassMember struct {
+	ID        uint      `gorm:"primaryKey"`
+	ClassID   uint      `gorm:"not null;uniqueIndex:idx_class_member_user"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_class_member_user;index"`
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+	Class     Class     `gorm:"foreignKey:ClassID;constraint:OnDelete:CASCADE;"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+// org roles only apply inside their organization, the global role still decides who can create quizzes
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"api_quiz/dto"
+	"api_quiz/utils/helper"
+	"net/http"
+)
+
+func actorFromClaims(claims *helper.JWTClaims) dto.Actor {
+	return dto.Actor{
+		UserID:  claims.UserID,
+		Role:    claims.Role,
+		OrgID:   claims.OrgID,
+		OrgRole: claims.OrgRole,
+	}
+}
+
+func clientInfo(r *http.Request) dto.ClientInfo {
+	return dto.ClientInfo{
+		IP:        helper.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
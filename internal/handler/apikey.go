@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"api_quiz/dto"
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/middleware"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler struct {
+	apiKeyUC usecase.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(apiKeyUC usecase.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyUC}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	var input dto.CreateAPIKey
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	response, err := h.apiKeyUC.CreateAPIKey(claims.UserID, claims.OrgID, &input)
+	if err != nil {
+		switch err {
+		case helper.ErrInvalidKeyName, helper.ErrInvalidScope, helper.ErrInvalidKeyExpiry:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case helper.ErrTooManyAPIKeys:
+			helper.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	response, err := h.apiKeyUC.GetAPIKeys(claims.UserID)
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	keyId, _ := strconv.Atoi(params["keyid"])
+
+	if err := h.apiKeyUC.RevokeAPIKey(claims.UserID, uint(keyId)); err != nil {
+		switch err {
+		case helper.ErrAPIKeyNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "api key has been revoked",
+	})
+}
@@ -7,6 +7,9 @@ import (
 	"api_quiz/utils/middleware"
 	"encoding/json"
 	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
 )
 
 type AuthHandler struct {
@@ -34,6 +37,9 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		case helper.ErrInvalidEmail:
 			helper.WriteError(w, http.StatusBadRequest, "invalid type email")
 			return
+		case helper.ErrPasswordTooShort, helper.ErrPasswordTooLong, helper.ErrPasswordTooCommon, helper.ErrPasswordPersonal:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+			return
 		default:
 			helper.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -56,12 +62,76 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		helper.WriteError(w, http.StatusBadRequest, "invalid body")
 		return
 	}
-	token, err := h.authUC.Login(&input)
+	input.Client = clientInfo(r)
+
+	response, err := h.authUC.Login(&input)
 	if err != nil {
 		switch err {
 		case helper.ErrInvalidEmail:
 			helper.WriteError(w, http.StatusBadRequest, "invalid type email")
 			return
+		case helper.ErrInvalidLogin, helper.ErrLoginThrottled:
+			// a locked account answers like a wrong password so the lockout does not confirm the email
+			helper.WriteError(w, http.StatusUnauthorized, helper.ErrInvalidLogin.Error())
+			return
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+
+}
+
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var input dto.RefreshToken
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.RefreshToken == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	input.Client = clientInfo(r)
+	response, err := h.authUC.RefreshToken(&input)
+	if err != nil {
+		switch err {
+		case helper.ErrInvalidRefreshToken, helper.ErrRefreshTokenReused, helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusUnauthorized, err.Error())
+			return
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "")
+		return
+	}
+
+	// refresh token is optional, without it only the access token is revoked
+	var input dto.RefreshToken
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := h.authUC.Logout(claims, input.RefreshToken); err != nil {
+		switch err {
+		case helper.ErrInvalidRefreshToken:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+			return
 		default:
 			helper.WriteError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -69,9 +139,25 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	helper.WriteJSON(w, http.StatusOK, map[string]string{
-		"token_jwt": token,
+		"message": "succed logout",
 	})
+}
+
+func (h *AuthHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "")
+		return
+	}
+
+	if err := h.authUC.RevokeAllSessions(claims.UserID); err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "all sessions has been revoked",
+	})
 }
 
 func (h *AuthHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
@@ -87,24 +173,206 @@ func (h *AuthHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	helper.WriteJSON(w, http.StatusOK, map[string]string{
-		"message": "succed delete this account",
+		"message": "succed delete this account, it can be restored from the link in your email for 30 days",
+	})
+}
+
+func (h *AuthHandler) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		helper.WriteError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := h.authUC.RestoreAccount(token); err != nil {
+		switch err {
+		case helper.ErrInvalidToken:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case helper.ErrRestoreExpired:
+			helper.WriteError(w, http.StatusGone, err.Error())
+			return
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "akun berhasil dikembalikan, silakan login lagi",
 	})
 }
 
 func (h *AuthHandler) Verification(w http.ResponseWriter, r *http.Request) {
-	tokenstring := r.URL.Query().Get("token")
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		helper.WriteError(w, http.StatusBadRequest, "token is required")
+		return
+	}
 
-	claims, err := helper.ParseJWT(tokenstring)
-	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, "failed verified jwt user")
+	if err := h.authUC.VerifyEmail(token); err != nil {
+		switch err {
+		case helper.ErrInvalidToken:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		case helper.ErrAlreadyVerified:
+			helper.WriteError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "user berhasil ter verifikasi, mantap!",
+	})
+}
+
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	var input dto.ResendVerification
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if err := h.authUC.ValidateUser(claims.UserID); err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+	if input.Email == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
 		return
 	}
 
+	if err := h.authUC.ResendVerification(input.Email); err != nil {
+		switch err {
+		case helper.ErrInvalidEmail:
+			helper.WriteError(w, http.StatusBadRequest, "invalid type email")
+			return
+		case helper.ErrTooManyRequests:
+			helper.WriteError(w, http.StatusTooManyRequests, err.Error())
+			return
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
 	helper.WriteJSON(w, http.StatusOK, map[string]string{
-		"message": "user berhasil ter verifikasi, mantap!",
+		"message": "if the account still needs verification, a new link has been sent",
+	})
+}
+
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var input dto.ForgotPassword
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.Email == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := h.authUC.ForgotPassword(input.Email); err != nil {
+		switch err {
+		case helper.ErrInvalidEmail:
+			helper.WriteError(w, http.StatusBadRequest, "invalid type email")
+			return
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "if the email is registered, a reset link has been sent",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var input dto.ResetPassword
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.Token == "" || input.Password == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := h.authUC.ResetPassword(&input); err != nil {
+		switch err {
+		case helper.ErrInvalidToken, helper.ErrPasswordTooShort, helper.ErrPasswordTooLong, helper.ErrPasswordTooCommon, helper.ErrPasswordPersonal:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "password has been reset, please login again",
+	})
+}
+
+func (h *AuthHandler) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		helper.WriteError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := h.authUC.UnlockAccount(token); err != nil {
+		switch err {
+		case helper.ErrInvalidToken:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "akun sudah dibuka, silakan login lagi",
+	})
+}
+
+func (h *AuthHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	userId, _ := strconv.Atoi(params["userid"])
+
+	var input dto.UpdateRole
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := h.authUC.UpdateRole(actorFromClaims(claims), uint(userId), input.Role); err != nil {
+		switch err {
+		case helper.ErrForbidden:
+			helper.WriteError(w, http.StatusForbidden, err.Error())
+		case helper.ErrInvalidRole:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "role has been updated",
 	})
 }
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"api_quiz/dto"
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/middleware"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type ClassHandler struct {
+	classUC usecase.ClassUseCase
+}
+
+func NewClassHandler(classUC usecase.ClassUseCase) *ClassHandler {
+	return &ClassHandler{classUC}
+}
+
+func (h *ClassHandler) CreateClass(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	var input dto.CreateClass
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	response, err := h.classUC.CreateClass(actorFromClaims(claims), &input)
+	if err != nil {
+		switch err {
+		case helper.ErrInvalidClassName:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case helper.ErrForbidden:
+			helper.WriteError(w, http.StatusForbidden, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *ClassHandler) GetMyClasses(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	response, err := h.classUC.GetMyClasses(actorFromClaims(claims))
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ClassHandler) GetClass(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	classId, _ := strconv.Atoi(params["classid"])
+
+	response, err := h.classUC.GetClass(actorFromClaims(claims), uint(classId))
+	if err != nil {
+		writeClassError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ClassHandler) RegenerateJoinCode(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	classId, _ := strconv.Atoi(params["classid"])
+
+	response, err := h.classUC.RegenerateJoinCode(actorFromClaims(claims), uint(classId))
+	if err != nil {
+		writeClassError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+// JoinClass takes the code from the body, or from the query of a shared join link.
+func (h *ClassHandler) JoinClass(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	input := dto.JoinClass{Code: r.URL.Query().Get("code")}
+	if input.Code == "" {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			helper.WriteError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+	}
+
+	response, err := h.classUC.JoinClass(actorFromClaims(claims), input.Code)
+	if err != nil {
+		writeClassError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ClassHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	classId, _ := strconv.Atoi(params["classid"])
+
+	response, err := h.classUC.GetMembers(actorFromClaims(claims), uint(classId))
+	if err != nil {
+		writeClassError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ClassHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	classId, _ := strconv.Atoi(params["classid"])
+
+	var input dto.AddClassMember
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := h.classUC.AddMember(actorFromClaims(claims), uint(classId), &input); err != nil {
+		writeClassError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusCreated, map[string]string{
+		"message": "student has been added to the class",
+	})
+}
+
+func (h *ClassHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	classId, _ := strconv.Atoi(params["classid"])
+	userId, _ := strconv.Atoi(params["userid"])
+
+	if err := h.classUC.RemoveMember(actorFromClaims(claims), uint(classId), uint(userId)); err != nil {
+		writeClassError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "student has been removed from the class",
+	})
+}
+
+func (h *ClassHandler) GetClassQuizzes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	classId, _ := strconv.Atoi(params["classid"])
+
+	response, err := h.classUC.GetClassQuizzes(actorFromClaims(claims), uint(classId))
+	if err != nil {
+		writeClassError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *ClassHandler) GetClassSubmissions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	classId, _ := strconv.Atoi(params["classid"])
+
+	response, err := h.classUC.GetClassSubmissions(actorFromClaims(claims), uint(classId))
+	if err != nil {
+		writeClassError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+// writeClassError maps the errors shared by the class endpoints.
+func writeClassError(w http.ResponseWriter, err error) {
+	switch err {
+	case helper.ErrInvalidJoinCode:
+		helper.WriteError(w, http.StatusBadRequest, err.Error())
+	case helper.ErrUnauhorized:
+		helper.WriteError(w, http.StatusUnauthorized, err.Error())
+	case helper.ErrNotOrgMember, helper.ErrJoinCodeRequired:
+		helper.WriteError(w, http.StatusForbidden, err.Error())
+	case helper.ErrClassNotFound, helper.ErrUserNotFound, helper.ErrNotInClass:
+		helper.WriteError(w, http.StatusNotFound, err.Error())
+	case helper.ErrAlreadyInClass:
+		helper.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/middleware"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type ExportHandler struct {
+	exportUC usecase.ExportUseCase
+}
+
+func NewExportHandler(exportUC usecase.ExportUseCase) *ExportHandler {
+	return &ExportHandler{exportUC}
+}
+
+func (h *ExportHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	response, err := h.exportUC.RequestExport(claims.UserID)
+	if err != nil {
+		switch err {
+		case helper.ErrExportPending:
+			helper.WriteError(w, http.StatusConflict, err.Error())
+		case helper.ErrTooManyRequests:
+			helper.WriteError(w, http.StatusTooManyRequests, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusAccepted, response)
+}
+
+func (h *ExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	exportId, _ := strconv.Atoi(params["exportid"])
+
+	response, err := h.exportUC.GetExport(claims.UserID, uint(exportId))
+	if err != nil {
+		switch err {
+		case helper.ErrExportNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+// Download is public, the token in the link is the only thing protecting the archive.
+func (h *ExportHandler) Download(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		helper.WriteError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	path, err := h.exportUC.Download(token)
+	if err != nil {
+		switch err {
+		case helper.ErrExportNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		case helper.ErrExportNotReady:
+			helper.WriteError(w, http.StatusConflict, err.Error())
+		case helper.ErrExportExpired:
+			helper.WriteError(w, http.StatusGone, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		helper.WriteError(w, http.StatusGone, helper.ErrExportExpired.Error())
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="api-quiz-export.zip"`)
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, "api-quiz-export.zip", info.ModTime(), file)
+}
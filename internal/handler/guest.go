@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"api_quiz/dto"
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/middleware"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type GuestHandler struct {
+	guestUC usecase.GuestUseCase
+}
+
+func NewGuestHandler(guestUC usecase.GuestUseCase) *GuestHandler {
+	return &GuestHandler{guestUC}
+}
+
+func (h *GuestHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	response, err := h.guestUC.IssueToken()
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *GuestHandler) GetPublicQuizzes(w http.ResponseWriter, r *http.Request) {
+	response, err := h.guestUC.GetPublicQuizzes()
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *GuestHandler) GetPublicQuiz(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	quizId, _ := strconv.Atoi(params["quizid"])
+
+	response, err := h.guestUC.GetPublicQuiz(uint(quizId))
+	if err != nil {
+		switch err {
+		case helper.ErrQuizNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *GuestHandler) CreateSubmission(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.GuestContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	quizId, _ := strconv.Atoi(params["quizid"])
+
+	var input dto.Submission
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	input.QuizID = uint(quizId)
+	input.GuestID = claims.Subject
+
+	response, err := h.guestUC.CreateSubmission(&input)
+	if err != nil {
+		switch err {
+		case helper.ErrQuizNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *GuestHandler) GetSubmissions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.GuestContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	response, err := h.guestUC.GetSubmissions(claims.Subject)
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *GuestHandler) Claim(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	var input dto.ClaimGuest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.GuestToken == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	response, err := h.guestUC.Claim(claims.UserID, input.GuestToken)
+	if err != nil {
+		switch err {
+		case helper.ErrInvalidToken:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"api_quiz/utils/helper"
+	"net/http"
+)
+
+type JWKSHandler struct {
+	keys *helper.KeyManager
+}
+
+func NewJWKSHandler(keys *helper.KeyManager) *JWKSHandler {
+	return &JWKSHandler{keys}
+}
+
+// JWKS lets other services verify our access tokens without sharing a secret.
+func (h *JWKSHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	helper.WriteJSON(w, http.StatusOK, h.keys.JWKS())
+}
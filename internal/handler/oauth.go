@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type OAuthHandler struct {
+	oauthUC usecase.OAuthUseCase
+}
+
+func NewOAuthHandler(oauthUC usecase.OAuthUseCase) *OAuthHandler {
+	return &OAuthHandler{oauthUC}
+}
+
+func (h *OAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	authURL, err := h.oauthUC.LoginURL(params["provider"])
+	if err != nil {
+		switch {
+		case errors.Is(err, helper.ErrUnknownProvider):
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, helper.ErrOAuthFailed):
+			helper.WriteError(w, http.StatusBadGateway, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	query := r.URL.Query()
+
+	if providerErr := query.Get("error"); providerErr != "" {
+		helper.WriteError(w, http.StatusUnauthorized, "provider denied login: "+providerErr)
+		return
+	}
+	if query.Get("state") == "" || query.Get("code") == "" {
+		helper.WriteError(w, http.StatusBadRequest, "state and code are required")
+		return
+	}
+
+	response, err := h.oauthUC.Callback(params["provider"], query.Get("state"), query.Get("code"), clientInfo(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, helper.ErrUnknownProvider):
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, helper.ErrInvalidOAuthState):
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, helper.ErrOAuthFailed), errors.Is(err, helper.ErrProviderEmailUnverify), errors.Is(err, helper.ErrUserNotFound):
+			helper.WriteError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, helper.ErrEmailTaken), errors.Is(err, helper.ErrUnverifiedAccount):
+			helper.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
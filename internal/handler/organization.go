@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"api_quiz/dto"
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/middleware"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type OrganizationHandler struct {
+	orgUC usecase.OrganizationUseCase
+}
+
+func NewOrganizationHandler(orgUC usecase.OrganizationUseCase) *OrganizationHandler {
+	return &OrganizationHandler{orgUC}
+}
+
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	var input dto.CreateOrganization
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	response, err := h.orgUC.CreateOrganization(actorFromClaims(claims), &input)
+	if err != nil {
+		switch err {
+		case helper.ErrInvalidOrg:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case helper.ErrUnauhorized:
+			helper.WriteError(w, http.StatusForbidden, err.Error())
+		case helper.ErrOrgSlugTaken:
+			helper.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *OrganizationHandler) GetMyOrganizations(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	response, err := h.orgUC.GetMyOrganizations(claims.UserID)
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *OrganizationHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	orgId, _ := strconv.Atoi(params["orgid"])
+
+	response, err := h.orgUC.GetMembers(actorFromClaims(claims), uint(orgId))
+	if err != nil {
+		writeOrgError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *OrganizationHandler) AddMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	orgId, _ := strconv.Atoi(params["orgid"])
+
+	var input dto.AddMember
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	response, err := h.orgUC.AddMember(actorFromClaims(claims), uint(orgId), &input)
+	if err != nil {
+		writeOrgError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *OrganizationHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	orgId, _ := strconv.Atoi(params["orgid"])
+	userId, _ := strconv.Atoi(params["userid"])
+
+	var input dto.UpdateMember
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := h.orgUC.UpdateMember(actorFromClaims(claims), uint(orgId), uint(userId), &input); err != nil {
+		writeOrgError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "member role has been updated",
+	})
+}
+
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	orgId, _ := strconv.Atoi(params["orgid"])
+	userId, _ := strconv.Atoi(params["userid"])
+
+	if err := h.orgUC.RemoveMember(actorFromClaims(claims), uint(orgId), uint(userId)); err != nil {
+		writeOrgError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "member has been removed",
+	})
+}
+
+func (h *OrganizationHandler) SwitchOrganization(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	var input dto.SwitchOrganization
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	response, err := h.orgUC.SwitchOrganization(claims.UserID, claims.SessionID, input.OrganizationID)
+	if err != nil {
+		writeOrgError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+// writeOrgError maps the errors shared by the member endpoints.
+func writeOrgError(w http.ResponseWriter, err error) {
+	switch err {
+	case helper.ErrInvalidOrgRole:
+		helper.WriteError(w, http.StatusBadRequest, err.Error())
+	case helper.ErrNotOrgMember, helper.ErrUnauhorized:
+		helper.WriteError(w, http.StatusForbidden, err.Error())
+	case helper.ErrUserNotFound:
+		helper.WriteError(w, http.StatusNotFound, err.Error())
+	case helper.ErrAlreadyMember, helper.ErrLastOwner:
+		helper.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
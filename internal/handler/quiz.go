@@ -21,13 +21,13 @@ func NewQuizHandler(quizUC usecase.QuizUseCase) *QuizHandler {
 }
 
 func (h *QuizHandler) GetAllQuiz(w http.ResponseWriter, r *http.Request) {
-	_, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
 	if !ok {
 		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
 		return
 	}
 
-	response, err := h.quizUC.GetAllQuiz()
+	response, err := h.quizUC.GetAllQuiz(actorFromClaims(claims))
 	if err != nil {
 		helper.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -37,7 +37,7 @@ func (h *QuizHandler) GetAllQuiz(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *QuizHandler) GetQuizById(w http.ResponseWriter, r *http.Request) {
-	_, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
 	if !ok {
 		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
 		return
@@ -46,9 +46,14 @@ func (h *QuizHandler) GetQuizById(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	quizId, _ := strconv.Atoi(params["quizid"])
 
-	response, err := h.quizUC.GetQuizFromId(uint(quizId))
+	response, err := h.quizUC.GetQuizFromId(actorFromClaims(claims), uint(quizId))
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case helper.ErrQuizNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -73,11 +78,20 @@ func (h *QuizHandler) CreateQuiz(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	input.Creator = claims.UserID
-
-	response, err := h.quizUC.CreateQuiz(&input)
+	response, err := h.quizUC.CreateQuiz(&input, actorFromClaims(claims))
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case helper.ErrForbidden:
+			helper.WriteError(w, http.StatusForbidden, err.Error())
+		case helper.ErrUnauhorized:
+			helper.WriteError(w, http.StatusUnauthorized, err.Error())
+		case helper.ErrClassNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		case helper.ErrInvalidRevealPolicy, helper.ErrInvalidUnanswered:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -101,17 +115,22 @@ func (h *QuizHandler) UpdateQuiz(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.Title == "" {
+	// every field is optional, only a body that changes nothing is rejected
+	if input.Title == "" && input.RevealPolicy == "" && input.IsPublic == nil && input.ClassID == nil && input.UnansweredPolicy == "" {
 		helper.WriteError(w, http.StatusBadRequest, "invalid body")
 		return
 	}
 
 	input.ID = uint(quizId)
-	response, err := h.quizUC.UpdateQuiz(&input, claims.UserID)
+	response, err := h.quizUC.UpdateQuiz(&input, actorFromClaims(claims))
 	if err != nil {
 		switch err {
 		case helper.ErrUnauhorized:
 			helper.WriteError(w, http.StatusUnauthorized, err.Error())
+		case helper.ErrQuizNotFound, helper.ErrClassNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		case helper.ErrInvalidRevealPolicy, helper.ErrInvalidUnanswered:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
 		default:
 			helper.WriteError(w, http.StatusInternalServerError, err.Error())
 		}
@@ -131,10 +150,12 @@ func (h *QuizHandler) DeleteQuiz(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	quizId, _ := strconv.Atoi(params["quizid"])
 
-	if err := h.quizUC.DeleteQuiz(claims.UserID, uint(quizId)); err != nil {
+	if err := h.quizUC.DeleteQuiz(actorFromClaims(claims), uint(quizId)); err != nil {
 		switch err {
 		case helper.ErrUnauhorized:
 			helper.WriteError(w, http.StatusUnauthorized, err.Error())
+		case helper.ErrQuizNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
 		default:
 			helper.WriteError(w, http.StatusInternalServerError, err.Error())
 		}
@@ -149,7 +170,7 @@ func (h *QuizHandler) DeleteQuiz(w http.ResponseWriter, r *http.Request) {
 
 // question
 func (h *QuizHandler) GetQuestionAnswerByQuizId(w http.ResponseWriter, r *http.Request) {
-	_, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
 	if !ok {
 		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
 		return
@@ -158,9 +179,14 @@ func (h *QuizHandler) GetQuestionAnswerByQuizId(w http.ResponseWriter, r *http.R
 	params := mux.Vars(r)
 	quizId, _ := strconv.Atoi(params["quizid"])
 
-	response, err := h.quizUC.GetQuestionAnswerByQuizId(uint(quizId))
+	response, err := h.quizUC.GetQuestionAnswerByQuizId(actorFromClaims(claims), uint(quizId))
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case helper.ErrQuizNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -168,7 +194,7 @@ func (h *QuizHandler) GetQuestionAnswerByQuizId(w http.ResponseWriter, r *http.R
 }
 
 func (h *QuizHandler) GetQuestionById(w http.ResponseWriter, r *http.Request) {
-	_, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
 	if !ok {
 		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
 		return
@@ -178,9 +204,9 @@ func (h *QuizHandler) GetQuestionById(w http.ResponseWriter, r *http.Request) {
 	questionId, _ := strconv.Atoi(params["questionid"])
 	quizId, _ := strconv.Atoi(params["quizid"])
 
-	response, err := h.quizUC.GetQuestionById(uint(questionId), uint(quizId))
+	response, err := h.quizUC.GetQuestionById(actorFromClaims(claims), uint(questionId), uint(quizId))
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		writeQuestionError(w, err)
 		return
 	}
 
@@ -207,37 +233,11 @@ func (h *QuizHandler) CreateQuestionAndAnswer(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if len(input.Answers) <= 2 {
-		helper.WriteError(w, http.StatusBadRequest, "at least one answer is required")
-		return
-	}
-	if len(input.Answers) > 5 {
-		helper.WriteError(w, http.StatusBadRequest, "max is 5")
-		return
-	}
-
-	correctAnswer := 0
-	for _, ans := range input.Answers {
-		if ans.IsCorrect {
-			correctAnswer++
-		}
-	}
-
-	if correctAnswer != 1 {
-		helper.WriteError(w, http.StatusBadRequest, "answer must 1")
-		return
-	}
-
 	input.QuizID = uint(quizId)
 
-	response, err := h.quizUC.CreateQuestionAndAnswer(&input, claims.UserID)
+	response, err := h.quizUC.CreateQuestionAndAnswer(&input, actorFromClaims(claims))
 	if err != nil {
-		switch err {
-		case helper.ErrUnauhorized:
-			helper.WriteError(w, http.StatusUnauthorized, err.Error())
-		default:
-			helper.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeQuestionError(w, err)
 		return
 	}
 
@@ -267,14 +267,9 @@ func (h *QuizHandler) UpdateQuestion(w http.ResponseWriter, r *http.Request) {
 
 	input.ID = uint(questionId)
 	input.QuizID = uint(quizId)
-	response, err := h.quizUC.UpdateQuestion(&input, claims.UserID)
+	response, err := h.quizUC.UpdateQuestion(&input, actorFromClaims(claims))
 	if err != nil {
-		switch err {
-		case helper.ErrUnauhorized:
-			helper.WriteError(w, http.StatusUnauthorized, err.Error())
-		default:
-			helper.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeQuestionError(w, err)
 		return
 	}
 
@@ -291,10 +286,12 @@ func (h *QuizHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
 	quizId, _ := strconv.Atoi(params["quizid"])
 	questionId, _ := strconv.Atoi(params["questionid"])
 
-	if err := h.quizUC.DeleteQuestion(uint(questionId), uint(quizId), claims.UserID); err != nil {
+	if err := h.quizUC.DeleteQuestion(uint(questionId), uint(quizId), actorFromClaims(claims)); err != nil {
 		switch err {
 		case helper.ErrUnauhorized:
 			helper.WriteError(w, http.StatusUnauthorized, err.Error())
+		case helper.ErrQuizNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
 		default:
 			helper.WriteError(w, http.StatusInternalServerError, err.Error())
 		}
@@ -306,9 +303,37 @@ func (h *QuizHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *QuizHandler) ReorderQuestions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+	params := mux.Vars(r)
+	quizId, _ := strconv.Atoi(params["quizid"])
+
+	var input dto.ReorderQuestions
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if len(input.QuestionIDs) == 0 {
+		helper.WriteError(w, http.StatusBadRequest, "question_ids cannot be empty")
+		return
+	}
+
+	response, err := h.quizUC.ReorderQuestions(actorFromClaims(claims), uint(quizId), input.QuestionIDs)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
 // answer
 func (h *QuizHandler) GetAnswerByQuestionId(w http.ResponseWriter, r *http.Request) {
-	_, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
 	if !ok {
 		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
 		return
@@ -317,9 +342,9 @@ func (h *QuizHandler) GetAnswerByQuestionId(w http.ResponseWriter, r *http.Reque
 	params := mux.Vars(r)
 	questionId, _ := strconv.Atoi(params["questionid"])
 
-	response, err := h.quizUC.GetAnswerByQuestionId(uint(questionId))
+	response, err := h.quizUC.GetAnswerByQuestionId(actorFromClaims(claims), uint(questionId))
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		writeQuestionError(w, err)
 		return
 	}
 
@@ -349,16 +374,9 @@ func (h *QuizHandler) UpdateAnswer(w http.ResponseWriter, r *http.Request) {
 
 	input.ID = uint(answerId)
 	input.QuestionID = uint(questionId)
-	response, err := h.quizUC.UpdateAnswer(claims.UserID, uint(quizId), input)
+	response, err := h.quizUC.UpdateAnswer(actorFromClaims(claims), uint(quizId), input)
 	if err != nil {
-		switch err {
-		case helper.ErrUnauhorized:
-			helper.WriteError(w, http.StatusUnauthorized, err.Error())
-		case helper.ErrCorrectAnswer:
-			helper.WriteError(w, http.StatusUnprocessableEntity, err.Error())
-		default:
-			helper.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeQuestionError(w, err)
 		return
 	}
 	helper.WriteJSON(w, http.StatusOK, response)
@@ -379,6 +397,10 @@ func (h *QuizHandler) AddAnswer(w http.ResponseWriter, r *http.Request) {
 		helper.WriteError(w, http.StatusBadRequest, "invalid body")
 		return
 	}
+	if len(input) == 0 {
+		helper.WriteError(w, http.StatusBadRequest, "answers cannot be empty")
+		return
+	}
 	if len(input) > 5 {
 		helper.WriteError(w, http.StatusBadRequest, "max 5 answer to request")
 		return
@@ -388,18 +410,9 @@ func (h *QuizHandler) AddAnswer(w http.ResponseWriter, r *http.Request) {
 		input[i].QuestionID = uint(questionId)
 	}
 
-	response, err := h.quizUC.AddAnswer(claims.UserID, uint(quizId), input)
+	response, err := h.quizUC.AddAnswer(actorFromClaims(claims), uint(quizId), input)
 	if err != nil {
-		switch err {
-		case helper.ErrUnauhorized:
-			helper.WriteError(w, http.StatusUnauthorized, err.Error())
-		case helper.ErrToomuchAnswer:
-			helper.WriteError(w, http.StatusUnprocessableEntity, err.Error())
-		case helper.ErrCorrectAnswer:
-			helper.WriteError(w, http.StatusUnprocessableEntity, err.Error())
-		default:
-			helper.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+		writeQuestionError(w, err)
 		return
 	}
 	helper.WriteJSON(w, http.StatusOK, response)
@@ -416,15 +429,8 @@ func (h *QuizHandler) DeleteAnswer(w http.ResponseWriter, r *http.Request) {
 	answerId, _ := strconv.Atoi(params["answerid"])
 	quizId, _ := strconv.Atoi(params["quizid"])
 
-	if err := h.quizUC.DeleteAnswer(uint(answerId), uint(questionId), uint(quizId), claims.UserID); err != nil {
-		switch err {
-		case helper.ErrUnauhorized:
-			helper.WriteError(w, http.StatusUnauthorized, err.Error())
-		case helper.ErrAnswerNotEnough:
-			helper.WriteError(w, http.StatusUnprocessableEntity, err.Error())
-		default:
-			helper.WriteError(w, http.StatusInternalServerError, err.Error())
-		}
+	if err := h.quizUC.DeleteAnswer(uint(answerId), uint(questionId), uint(quizId), actorFromClaims(claims)); err != nil {
+		writeQuestionError(w, err)
 		return
 	}
 
@@ -432,3 +438,48 @@ func (h *QuizHandler) DeleteAnswer(w http.ResponseWriter, r *http.Request) {
 		"message": "succed delete this answer",
 	})
 }
+
+func (h *QuizHandler) ReorderAnswers(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+	params := mux.Vars(r)
+	quizId, _ := strconv.Atoi(params["quizid"])
+	questionId, _ := strconv.Atoi(params["questionid"])
+
+	var input dto.ReorderAnswers
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if len(input.AnswerIDs) == 0 {
+		helper.WriteError(w, http.StatusBadRequest, "answer_ids cannot be empty")
+		return
+	}
+
+	response, err := h.quizUC.ReorderAnswers(actorFromClaims(claims), uint(quizId), uint(questionId), input.AnswerIDs)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func writeQuestionError(w http.ResponseWriter, err error) {
+	switch err {
+	case helper.ErrUnauhorized:
+		helper.WriteError(w, http.StatusUnauthorized, err.Error())
+	case helper.ErrQuizNotFound, helper.ErrQuestionNotFound, helper.ErrAnswerNotFound:
+		helper.WriteError(w, http.StatusNotFound, err.Error())
+	case helper.ErrInvalidQuestionType, helper.ErrInvalidMatchMode, helper.ErrInvalidRegex, helper.ErrInvalidTolerance, helper.ErrInvalidPoints, helper.ErrInvalidPenalty,
+		helper.ErrAnswerNotEnough, helper.ErrToomuchAnswer, helper.ErrCorrectAnswer, helper.ErrNoCorrectAnswer,
+		helper.ErrTrueFalseAnswer, helper.ErrAcceptedAnswer, helper.ErrNumericAnswer, helper.ErrInvalidRank, helper.ErrInvalidMatch,
+		helper.ErrQuestionOrder, helper.ErrAnswerOrder:
+		helper.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/middleware"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// a csv of 1000 rows stays far below this
+const maxRosterUploadSize = 2 << 20
+
+type RosterHandler struct {
+	rosterUC usecase.RosterUseCase
+}
+
+func NewRosterHandler(rosterUC usecase.RosterUseCase) *RosterHandler {
+	return &RosterHandler{rosterUC}
+}
+
+// ImportRoster accepts the csv as a multipart "file" field or as the raw request body.
+func (h *RosterHandler) ImportRoster(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRosterUploadSize)
+	var csvData io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			helper.WriteError(w, http.StatusBadRequest, "invalid file")
+			return
+		}
+		defer file.Close()
+		csvData = file
+	}
+
+	response, err := h.rosterUC.ImportRoster(actorFromClaims(claims), csvData)
+	if err != nil {
+		switch err {
+		case helper.ErrInvalidCSV, helper.ErrEmptyRoster, helper.ErrTooManyRows:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case helper.ErrForbidden:
+			helper.WriteError(w, http.StatusForbidden, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
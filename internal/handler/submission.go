@@ -21,13 +21,29 @@ func NewSubmissionHandler(submissionUC usecase.SubmissionUseCase) *SubmissionHan
 }
 
 func (h *SubmissionHandler) GetAllSubmission(w http.ResponseWriter, r *http.Request) {
-	_, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	response, err := h.submissionUC.GetAllSubmission(actorFromClaims(claims))
+	if err != nil {
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *SubmissionHandler) GetMySubmission(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
 	if !ok {
 		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
 		return
 	}
 
-	response, err := h.submissionUC.GetAllSubmission()
+	response, err := h.submissionUC.GetMySubmission(actorFromClaims(claims))
 	if err != nil {
 		helper.WriteError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -35,8 +51,35 @@ func (h *SubmissionHandler) GetAllSubmission(w http.ResponseWriter, r *http.Requ
 
 	helper.WriteJSON(w, http.StatusOK, response)
 }
+
+func (h *SubmissionHandler) GetSubmissionByQuizId(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	params := mux.Vars(r)
+	quizId, _ := strconv.Atoi(params["quizid"])
+
+	response, err := h.submissionUC.GetSubmissionByQuizId(actorFromClaims(claims), uint(quizId))
+	if err != nil {
+		switch err {
+		case helper.ErrQuizNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		case helper.ErrUnauhorized:
+			helper.WriteError(w, http.StatusUnauthorized, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
 func (h *SubmissionHandler) GetSubmissionById(w http.ResponseWriter, r *http.Request) {
-	_, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
 	if !ok {
 		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
 		return
@@ -45,9 +88,16 @@ func (h *SubmissionHandler) GetSubmissionById(w http.ResponseWriter, r *http.Req
 	params := mux.Vars(r)
 	submissionId, _ := strconv.Atoi(params["submissionid"])
 
-	response, err := h.submissionUC.GetSubmissionById(uint(submissionId))
+	response, err := h.submissionUC.GetSubmissionById(actorFromClaims(claims), uint(submissionId))
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case helper.ErrSubmissionNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		case helper.ErrUnauhorized:
+			helper.WriteError(w, http.StatusUnauthorized, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -72,10 +122,17 @@ func (h *SubmissionHandler) CreateSubmission(w http.ResponseWriter, r *http.Requ
 	}
 	input.QuizID = uint(quizId)
 	input.UserID = claims.UserID
+	input.OrgID = claims.OrgID
 
-	response, err := h.submissionUC.CreateSubmission(&input)
+	response, err := h.submissionUC.CreateSubmission(&input, actorFromClaims(claims))
 	if err != nil {
-		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case helper.ErrQuizNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
 	}
 
 	helper.WriteJSON(w, http.StatusCreated, response)
@@ -97,13 +154,21 @@ func (h *SubmissionHandler) UpdateSubmission(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if input.Points == nil && input.Score == nil {
+		helper.WriteError(w, http.StatusBadRequest, "points or score is required")
+		return
+	}
+
 	input.SubmissionID = uint(submisionId)
-	response, err := h.submissionUC.UpdateSubmision(&input, claims.UserID)
+	response, err := h.submissionUC.UpdateSubmision(&input, actorFromClaims(claims))
 	if err != nil {
 		switch err {
-		case helper.ErrQuizNotFound:
+		case helper.ErrQuizNotFound, helper.ErrSubmissionNotFound:
 			helper.WriteError(w, http.StatusNotFound, err.Error())
 			return
+		case helper.ErrNoMaxPoints:
+			helper.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+			return
 		case helper.ErrUnauhorized:
 			helper.WriteError(w, http.StatusUnauthorized, err.Error())
 			return
@@ -127,7 +192,7 @@ func (h *SubmissionHandler) DeleteSubmission(w http.ResponseWriter, r *http.Requ
 	params := mux.Vars(r)
 	submisionId, _ := strconv.Atoi(params["submissionid"])
 
-	if err := h.submissionUC.DeleteSubmision(uint(submisionId), claims.UserID); err != nil {
+	if err := h.submissionUC.DeleteSubmision(uint(submisionId), actorFromClaims(claims)); err != nil {
 		switch err {
 		case helper.ErrQuizNotFound:
 			helper.WriteError(w, http.StatusNotFound, err.Error())
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"api_quiz/dto"
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/middleware"
+	"encoding/json"
+	"net/http"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUC usecase.TwoFactorUseCase
+}
+
+func NewTwoFactorHandler(twoFactorUC usecase.TwoFactorUseCase) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorUC}
+}
+
+func (h *TwoFactorHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "")
+		return
+	}
+
+	response, err := h.twoFactorUC.Enroll(claims.UserID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "")
+		return
+	}
+
+	var input dto.TwoFactorCode
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Code == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	response, err := h.twoFactorUC.Confirm(claims.UserID, input.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "")
+		return
+	}
+
+	var input dto.TwoFactorCode
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Code == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := h.twoFactorUC.Disable(claims.UserID, input.Code); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "two factor has been disabled",
+	})
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "")
+		return
+	}
+
+	var input dto.TwoFactorCode
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.Code == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	response, err := h.twoFactorUC.RegenerateRecoveryCodes(claims.UserID, input.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *TwoFactorHandler) VerifyLogin(w http.ResponseWriter, r *http.Request) {
+	var input dto.TwoFactorLogin
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.ChallengeToken == "" || input.Code == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	input.Client = clientInfo(r)
+	response, err := h.twoFactorUC.VerifyLogin(&input)
+	if err != nil {
+		switch err {
+		case helper.ErrInvalidToken, helper.ErrInvalidTOTPCode, helper.ErrTOTPNotEnabled, helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusUnauthorized, err.Error())
+		case helper.ErrLoginThrottled:
+			// a locked account looks like a wrong code, the owner learns about it from the unlock mail
+			helper.WriteError(w, http.StatusUnauthorized, helper.ErrInvalidTOTPCode.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	switch err {
+	case helper.ErrInvalidTOTPCode:
+		helper.WriteError(w, http.StatusBadRequest, err.Error())
+	case helper.ErrTOTPAlreadyEnabled, helper.ErrTOTPNotEnabled, helper.ErrTOTPNotEnrolled:
+		helper.WriteError(w, http.StatusConflict, err.Error())
+	case helper.ErrUserNotFound:
+		helper.WriteError(w, http.StatusNotFound, err.Error())
+	default:
+		helper.WriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"api_quiz/dto"
+	"api_quiz/internal/usecase"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/middleware"
+	"encoding/json"
+	"net/http"
+)
+
+type UserHandler struct {
+	userUC usecase.UserUseCase
+}
+
+func NewUserHandler(userUC usecase.UserUseCase) *UserHandler {
+	return &UserHandler{userUC}
+}
+
+func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	response, err := h.userUC.GetProfile(claims.UserID)
+	if err != nil {
+		switch err {
+		case helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	var input dto.UpdateProfile
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	response, err := h.userUC.UpdateProfile(claims.UserID, &input)
+	if err != nil {
+		switch err {
+		case helper.ErrInvalidUsername, helper.ErrInvalidProfile:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case helper.ErrUsernameTaken:
+			helper.WriteError(w, http.StatusConflict, err.Error())
+		case helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	var input dto.ChangePassword
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if input.CurrentPassword == "" || input.NewPassword == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := h.userUC.ChangePassword(claims.UserID, &input); err != nil {
+		switch err {
+		case helper.ErrWrongPassword, helper.ErrPasswordTooShort, helper.ErrPasswordTooLong, helper.ErrPasswordTooCommon, helper.ErrPasswordPersonal:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "password has been changed, please login again",
+	})
+}
+
+func (h *UserHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*helper.JWTClaims)
+	if !ok {
+		helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+		return
+	}
+
+	var input dto.ChangeEmail
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	if input.NewEmail == "" || input.Password == "" {
+		helper.WriteError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+
+	if err := h.userUC.ChangeEmail(claims.UserID, &input); err != nil {
+		switch err {
+		case helper.ErrInvalidEmail:
+			helper.WriteError(w, http.StatusBadRequest, "invalid type email")
+		case helper.ErrWrongPassword, helper.ErrSameEmail:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case helper.ErrEmailTaken:
+			helper.WriteError(w, http.StatusConflict, err.Error())
+		case helper.ErrTooManyRequests:
+			helper.WriteError(w, http.StatusTooManyRequests, err.Error())
+		case helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "link konfirmasi sudah dikirim ke email baru",
+	})
+}
+
+func (h *UserHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		helper.WriteError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := h.userUC.ConfirmEmailChange(token); err != nil {
+		switch err {
+		case helper.ErrInvalidToken, helper.ErrNoPendingEmail:
+			helper.WriteError(w, http.StatusBadRequest, err.Error())
+		case helper.ErrEmailTaken:
+			helper.WriteError(w, http.StatusConflict, err.Error())
+		case helper.ErrUserNotFound:
+			helper.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			helper.WriteError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	helper.WriteJSON(w, http.StatusOK, map[string]string{
+		"message": "email berhasil diganti",
+	})
+}
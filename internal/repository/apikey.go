@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(key *entity.APIKey) error
+	GetAPIKeysByUser(userId uint) ([]entity.APIKey, error)
+	CountActiveAPIKeys(userId uint) (int64, error)
+	RevokeAPIKey(userId, id uint) error
+
+	//auth
+	GetAPIKeyByHash(hash string) (*entity.APIKey, error)
+	TouchAPIKey(id uint, at time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+func (r *apiKeyRepository) CreateAPIKey(key *entity.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) GetAPIKeysByUser(userId uint) ([]entity.APIKey, error) {
+	var keys []entity.APIKey
+	if err := r.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) CountActiveAPIKeys(userId uint) (int64, error) {
+	var total int64
+	if err := r.db.Model(&entity.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userId, time.Now()).
+		Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(userId, id uint) error {
+	revoked := r.db.Model(&entity.APIKey{}).Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userId).Update("revoked_at", time.Now())
+	if revoked.Error != nil {
+		return revoked.Error
+	}
+	if revoked.RowsAffected == 0 {
+		return helper.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// GetAPIKeyByHash loads the owner too, the middleware needs the current role and verification state.
+func (r *apiKeyRepository) GetAPIKeyByHash(hash string) (*entity.APIKey, error) {
+	var key entity.APIKey
+	if err := r.db.Preload("User").Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *apiKeyRepository) TouchAPIKey(id uint, at time.Time) error {
+	return r.db.Model(&entity.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -4,6 +4,8 @@ import (
 	"api_quiz/dto"
 	"api_quiz/entity"
 	"api_quiz/utils/helper"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -12,8 +14,24 @@ type AuthRepository interface {
 	Register(dto *dto.Register) (*entity.User, error)
 	Login(input *dto.Login) (*entity.User, error)
 	DeleteUser(id uint) error
+	GetUserById(id uint) (*entity.User, error)
+	GetUserByEmail(email string) (*entity.User, error)
+	UpdatePassword(id uint, hashed string) error
+	UpdateRole(id uint, role string) error
 
 	ValidateUser(id uint) error
+
+	//profile
+	GetUserByUsername(username string) (*entity.User, error)
+	UpdateProfile(id uint, fields map[string]any) error
+	SetPendingEmail(id uint, email string) error
+	ConfirmEmailChange(id uint, email string) error
+	EmailExists(email string) (bool, error)
+
+	//deleted account
+	RestoreUser(id uint, deletedAfter time.Time) error
+	GetUsersToPurge(deletedBefore time.Time, limit int) ([]entity.User, error)
+	AnonymizeUser(user *entity.User) error
 }
 
 type authRepository struct {
@@ -29,6 +47,7 @@ func (r *authRepository) Register(dto *dto.Register) (*entity.User, error) {
 		Email:    dto.Email,
 		Password: dto.Password,
 		Username: dto.Username,
+		Role:     dto.Role,
 	}
 	if err := r.db.Create(&user).Error; err != nil {
 		return nil, err
@@ -44,6 +63,7 @@ func (r *authRepository) Login(input *dto.Login) (*entity.User, error) {
 		if err == gorm.ErrRecordNotFound {
 			return nil, helper.ErrUserNotFound
 		}
+		return nil, err
 	}
 
 	return &user, nil
@@ -60,17 +80,180 @@ func (r *authRepository) DeleteUser(id uint) error {
 	return nil
 }
 
-func (r *authRepository) ValidateUser(id uint) error {
-	err := r.db.Model(&entity.User{}).Where("id = ?", id).Update("is_verified", true).Error
+func (r *authRepository) GetUserById(id uint) (*entity.User, error) {
+	var user entity.User
+	if err := r.db.Where("id = ?", id).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrUserNotFound
+		}
+		return nil, err
+	}
 
-	if err != nil {
+	return &user, nil
+}
+
+func (r *authRepository) GetUserByEmail(email string) (*entity.User, error) {
+	var user entity.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *authRepository) UpdatePassword(id uint, hashed string) error {
+	updated := r.db.Model(&entity.User{}).Where("id = ?", id).Update("password", hashed)
+	if updated.Error != nil {
+		return updated.Error
+	}
+	if updated.RowsAffected == 0 {
+		return helper.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *authRepository) UpdateRole(id uint, role string) error {
+	var user entity.User
+	if err := r.db.Select("id").Where("id = ?", id).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return helper.ErrUserNotFound
 		}
+		return err
+	}
+
+	return r.db.Model(&entity.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+func (r *authRepository) ValidateUser(id uint) error {
+	updated := r.db.Model(&entity.User{}).Where("id = ? AND is_verified = ?", id, false).Update("is_verified", true)
+	if updated.Error != nil {
+		return updated.Error
+	}
+	if updated.RowsAffected == 0 {
+		return helper.ErrAlreadyVerified
+	}
+
+	return nil
+}
+
+// GetUserByUsername also sees deleted accounts, their username stays reserved until the purge.
+func (r *authRepository) GetUserByUsername(username string) (*entity.User, error) {
+	var user entity.User
+	if err := r.db.Unscoped().Where("username = ?", username).First(&user).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *authRepository) UpdateProfile(id uint, fields map[string]any) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	updated := r.db.Model(&entity.User{}).Where("id = ?", id).Updates(fields)
+	if updated.Error != nil {
+		return updated.Error
+	}
+	if updated.RowsAffected == 0 {
+		if _, err := r.GetUserById(id); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+func (r *authRepository) SetPendingEmail(id uint, email string) error {
+	return r.db.Model(&entity.User{}).Where("id = ?", id).Update("pending_email", email).Error
+}
+
+// ConfirmEmailChange only swaps when the pending email is still the one the link was sent to.
+func (r *authRepository) ConfirmEmailChange(id uint, email string) error {
+	updated := r.db.Model(&entity.User{}).Where("id = ? AND pending_email = ?", id, email).Updates(map[string]any{
+		"email":         email,
+		"pending_email": "",
+	})
+	if updated.Error != nil {
+		return updated.Error
+	}
+	if updated.RowsAffected == 0 {
+		return helper.ErrNoPendingEmail
+	}
+
+	return nil
+}
+
+// EmailExists also counts deleted accounts, the unique index still holds their email.
+func (r *authRepository) EmailExists(email string) (bool, error) {
+	var total int64
+	if err := r.db.Unscoped().Model(&entity.User{}).Where("email = ?", email).Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+func (r *authRepository) RestoreUser(id uint, deletedAfter time.Time) error {
+	restored := r.db.Unscoped().Model(&entity.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL AND deleted_at >= ? AND anonymized_at IS NULL", id, deletedAfter).
+		Update("deleted_at", nil)
+	if restored.Error != nil {
+		return restored.Error
+	}
+	if restored.RowsAffected == 0 {
+		return helper.ErrRestoreExpired
+	}
+
+	return nil
+}
+
+func (r *authRepository) GetUsersToPurge(deletedBefore time.Time, limit int) ([]entity.User, error) {
+	var users []entity.User
+	if err := r.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND anonymized_at IS NULL", deletedBefore).
+		Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// AnonymizeUser keeps the row so quizzes and submissions still point at something, but strips
+// everything that identifies the person and drops their credentials.
+func (r *authRepository) AnonymizeUser(user *entity.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&entity.User{}).Where("id = ?", user.ID).Updates(map[string]any{
+			"email":          fmt.Sprintf("deleted-%d@deleted.invalid", user.ID),
+			"username":       fmt.Sprintf("deleted_user_%d", user.ID),
+			"password":       "",
+			"display_name":   "Deleted user",
+			"avatar_url":     "",
+			"locale":         "",
+			"time_zone":      "",
+			"pending_email":  "",
+			"is_verified":    false,
+			"totp_secret":    "",
+			"totp_enabled":   false,
+			"totp_last_step": 0,
+			"anonymized_at":  time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []any{&entity.RefreshToken{}, &entity.Session{}, &entity.UserToken{}, &entity.APIKey{}, &entity.RecoveryCode{}, &entity.UserIdentity{}, &entity.Membership{}, &entity.ClassMember{}} {
+			if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("email = ?", user.Email).Delete(&entity.FailedLogin{}).Error
+	})
+}
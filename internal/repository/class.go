@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+
+	"gorm.io/gorm"
+)
+
+type ClassRepository interface {
+	CreateClass(class *entity.Class) error
+	GetClassById(orgId, classId uint) (*entity.Class, error)
+	GetClassByJoinCode(orgId uint, code string) (*entity.Class, error)
+	GetClassesByName(orgId uint, name string) ([]entity.Class, error)
+	GetUserClasses(orgId, userId uint) ([]entity.Class, error)
+	UpdateJoinCode(classId uint, code string) error
+
+	//roster
+	IsClassMember(classId, userId uint) (bool, error)
+	GetClassMembers(classId uint) ([]dto.ClassMemberResponse, error)
+	AddClassMember(classId, userId uint) error
+	RemoveClassMember(classId, userId uint) error
+}
+
+type classRepository struct {
+	db *gorm.DB
+}
+
+func NewClassRepository(db *gorm.DB) ClassRepository {
+	return &classRepository{db}
+}
+
+// classesOf is the subquery of classes a user teaches or joined.
+func classesOf(db *gorm.DB, userId uint) *gorm.DB {
+	joined := db.Model(&entity.ClassMember{}).Select("class_id").Where("user_id = ?", userId)
+	return db.Model(&entity.Class{}).Select("id").Where("teacher_id = ? OR id IN (?)", userId, joined)
+}
+
+// quizVisibleTo hides quizzes restricted to a class the user is not part of, creators always see their own.
+func quizVisibleTo(db *gorm.DB, userId uint) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		return query.Where("class_id IS NULL OR creator_id = ? OR class_id IN (?)", userId, classesOf(db, userId))
+	}
+}
+
+func (r *classRepository) CreateClass(class *entity.Class) error {
+	return r.db.Create(class).Error
+}
+
+func (r *classRepository) GetClassById(orgId, classId uint) (*entity.Class, error) {
+	return r.findClass(r.db.Scopes(inOrg(orgId)).Where("id = ?", classId))
+}
+
+func (r *classRepository) GetClassByJoinCode(orgId uint, code string) (*entity.Class, error) {
+	return r.findClass(r.db.Scopes(inOrg(orgId)).Where("join_code = ?", code))
+}
+
+func (r *classRepository) GetClassesByName(orgId uint, name string) ([]entity.Class, error) {
+	var classes []entity.Class
+	if err := r.db.Scopes(inOrg(orgId)).Where("name = ?", name).Find(&classes).Error; err != nil {
+		return nil, err
+	}
+
+	return classes, nil
+}
+
+func (r *classRepository) findClass(query *gorm.DB) (*entity.Class, error) {
+	var class entity.Class
+	if err := query.First(&class).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrClassNotFound
+		}
+		return nil, err
+	}
+
+	return &class, nil
+}
+
+func (r *classRepository) GetUserClasses(orgId, userId uint) ([]entity.Class, error) {
+	var classes []entity.Class
+	if err := r.db.Scopes(inOrg(orgId)).Where("id IN (?)", classesOf(r.db, userId)).Order("created_at").Find(&classes).Error; err != nil {
+		return nil, err
+	}
+
+	return classes, nil
+}
+
+func (r *classRepository) UpdateJoinCode(classId uint, code string) error {
+	return r.db.Model(&entity.Class{}).Where("id = ?", classId).Update("join_code", code).Error
+}
+
+func (r *classRepository) IsClassMember(classId, userId uint) (bool, error) {
+	var total int64
+	if err := r.db.Model(&entity.ClassMember{}).Where("class_id = ? AND user_id = ?", classId, userId).Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+func (r *classRepository) GetClassMembers(classId uint) ([]dto.ClassMemberResponse, error) {
+	var members []entity.ClassMember
+	if err := r.db.Preload("User").Where("class_id = ?", classId).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ClassMemberResponse, 0, len(members))
+	for _, m := range members {
+		// the user is not preloaded when the account was deleted
+		if m.User.ID == 0 {
+			continue
+		}
+		response = append(response, dto.ClassMemberResponse{
+			UserID:      m.User.ID,
+			Username:    m.User.Username,
+			DisplayName: m.User.DisplayName,
+			Email:       m.User.Email,
+			JoinedAt:    m.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+func (r *classRepository) AddClassMember(classId, userId uint) error {
+	member, err := r.IsClassMember(classId, userId)
+	if err != nil {
+		return err
+	}
+	if member {
+		return helper.ErrAlreadyInClass
+	}
+
+	return r.db.Create(&entity.ClassMember{ClassID: classId, UserID: userId}).Error
+}
+
+func (r *classRepository) RemoveClassMember(classId, userId uint) error {
+	deleted := r.db.Where("class_id = ? AND user_id = ?", classId, userId).Delete(&entity.ClassMember{})
+	if deleted.Error != nil {
+		return deleted.Error
+	}
+	if deleted.RowsAffected == 0 {
+		return helper.ErrNotInClass
+	}
+
+	return nil
+}
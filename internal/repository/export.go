@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ExportRepository interface {
+	CreateExport(export *entity.DataExport) error
+	SaveExport(export *entity.DataExport) error
+	GetExportById(userId, id uint) (*entity.DataExport, error)
+	GetExportByTokenHash(hash string) (*entity.DataExport, error)
+	HasPendingExport(userId uint) (bool, error)
+	CountExportsSince(userId uint, since time.Time) (int64, error)
+	GetExpiredExports(now time.Time) ([]entity.DataExport, error)
+	FailStaleExports(createdBefore time.Time) error
+
+	//data
+	GetQuizzesByCreator(userId uint) ([]dto.QuizResponseWithQS, error)
+	GetSubmissionsByUser(userId uint) ([]dto.ExportSubmission, error)
+}
+
+type exportRepository struct {
+	db *gorm.DB
+}
+
+func NewExportRepository(db *gorm.DB) ExportRepository {
+	return &exportRepository{db}
+}
+
+func (r *exportRepository) CreateExport(export *entity.DataExport) error {
+	return r.db.Create(export).Error
+}
+
+func (r *exportRepository) SaveExport(export *entity.DataExport) error {
+	return r.db.Save(export).Error
+}
+
+func (r *exportRepository) GetExportById(userId, id uint) (*entity.DataExport, error) {
+	var export entity.DataExport
+	if err := r.db.Where("id = ? AND user_id = ?", id, userId).First(&export).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrExportNotFound
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *exportRepository) GetExportByTokenHash(hash string) (*entity.DataExport, error) {
+	var export entity.DataExport
+	if err := r.db.Where("token_hash = ?", hash).First(&export).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrExportNotFound
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *exportRepository) HasPendingExport(userId uint) (bool, error) {
+	var total int64
+	if err := r.db.Model(&entity.DataExport{}).Where("user_id = ? AND status = ?", userId, entity.ExportPending).Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+func (r *exportRepository) CountExportsSince(userId uint, since time.Time) (int64, error) {
+	var total int64
+	if err := r.db.Model(&entity.DataExport{}).Where("user_id = ? AND created_at >= ?", userId, since).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *exportRepository) GetExpiredExports(now time.Time) ([]entity.DataExport, error) {
+	var exports []entity.DataExport
+	if err := r.db.Where("status = ? AND expires_at < ?", entity.ExportReady, now).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+
+	return exports, nil
+}
+
+// FailStaleExports gives up on exports whose builder died with the process.
+func (r *exportRepository) FailStaleExports(createdBefore time.Time) error {
+	return r.db.Model(&entity.DataExport{}).
+		Where("status = ? AND created_at < ?", entity.ExportPending, createdBefore).
+		Update("status", entity.ExportFailed).Error
+}
+
+func (r *exportRepository) GetQuizzesByCreator(userId uint) ([]dto.QuizResponseWithQS, error) {
+	var quizzes []entity.Quiz
+	if err := r.db.Preload("Questions", byPosition).Preload("Questions.Answers", byPosition).Where("creator_id = ?", userId).Order("id").Find(&quizzes).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.QuizResponseWithQS, len(quizzes))
+	for i, quiz := range quizzes {
+		questions := make([]dto.QuestionResponse, len(quiz.Questions))
+		for j, q := range quiz.Questions {
+			questions[j] = toQuestionResponse(q)
+		}
+
+		response[i] = dto.QuizResponseWithQS{
+			ID:               quiz.ID,
+			Creator:          quiz.CreatorID,
+			Title:            quiz.Title,
+			RevealPolicy:     quiz.RevealPolicy,
+			UnansweredPolicy: quiz.UnansweredPolicy,
+			Question:         questions,
+		}
+	}
+
+	return response, nil
+}
+
+func (r *exportRepository) GetSubmissionsByUser(userId uint) ([]dto.ExportSubmission, error) {
+	var submissions []entity.Submission
+	if err := r.db.Preload("Answers").Preload("Quiz").Where("user_id = ?", userId).Order("created_at").Find(&submissions).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ExportSubmission, len(submissions))
+	for i, s := range submissions {
+		answers := make([]dto.SubmissionAnswerResponse, len(s.Answers))
+		for j, ans := range s.Answers {
+			answers[j] = toSubmissionAnswerResponse(ans)
+		}
+
+		response[i] = dto.ExportSubmission{
+			SubmissionResponse: dto.SubmissionResponse{
+				ID:        s.ID,
+				QuizID:    s.QuizID,
+				UserID:    ownerId(s.UserID),
+				Points:    s.Points,
+				MaxPoints: s.MaxPoints,
+				Score:     s.Score,
+				CreatedAt: s.CreatedAt,
+				UpdatedAt: s.UpdatedAt,
+				Answers:   answers,
+			},
+			QuizTitle:    s.Quiz.Title,
+			RevealPolicy: s.Quiz.RevealPolicy,
+			QuizCreator:  s.Quiz.CreatorID,
+		}
+	}
+
+	return response, nil
+}
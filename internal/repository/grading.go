@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+)
+
+// numeric replies are compared with a little room for float rounding on top of the tolerance
+const numericEpsilon = 1e-9
+
+// gradeAnswer checks one reply against the key of its question, every type is all or nothing.
+func gradeAnswer(question entity.Question, reply dto.SubmissionAnswer) entity.SubmissionUserAnswer {
+	graded := entity.SubmissionUserAnswer{QuestionID: question.ID}
+
+	switch question.Type {
+	case entity.QuestionMultiSelect:
+		var correct []uint
+		for _, ans := range question.Answers {
+			if ans.IsCorrect {
+				correct = append(correct, ans.ID)
+			}
+		}
+		graded.IsCorrect = sameIDSet(reply.AnswerIDs, correct)
+		graded.CorrectIDs = encodeJSON(correct)
+		graded.Response = encodeJSON(dto.SubmissionAnswer{AnswerIDs: reply.AnswerIDs})
+
+	case entity.QuestionOrdering:
+		// ranks are unique (validateQuestion), the id only settles ties left by rows written outside the api
+		items := append([]entity.Answer(nil), question.Answers...)
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].CorrectRank != items[j].CorrectRank {
+				return items[i].CorrectRank < items[j].CorrectRank
+			}
+			return items[i].ID < items[j].ID
+		})
+		expected := make([]uint, len(items))
+		for i, item := range items {
+			expected[i] = item.ID
+		}
+		graded.IsCorrect = sameSequence(reply.AnswerIDs, expected)
+		graded.CorrectIDs = encodeJSON(expected)
+		graded.Response = encodeJSON(dto.SubmissionAnswer{AnswerIDs: reply.AnswerIDs})
+
+	case entity.QuestionShortText:
+		for _, ans := range question.Answers {
+			if matchesAcceptedAnswer(reply.Text, ans.Text, question.MatchMode) {
+				graded.IsCorrect = true
+				break
+			}
+		}
+		graded.Response = encodeJSON(dto.SubmissionAnswer{Text: reply.Text})
+
+	case entity.QuestionNumeric:
+		if len(question.Answers) > 0 {
+			got, errGot := helper.ParseNumber(reply.Text)
+			want, errWant := helper.ParseNumber(question.Answers[0].Text)
+			graded.IsCorrect = errGot == nil && errWant == nil && math.Abs(got-want) <= question.Tolerance+numericEpsilon
+		}
+		graded.Response = encodeJSON(dto.SubmissionAnswer{Text: reply.Text})
+
+	case entity.QuestionMatching:
+		graded.IsCorrect = matchesAllPairs(question.Answers, reply.Pairs)
+		graded.Response = encodeJSON(dto.SubmissionAnswer{Pairs: reply.Pairs})
+
+	default:
+		// single choice and true false
+		for _, ans := range question.Answers {
+			if ans.IsCorrect {
+				graded.CorrectID = ans.ID
+				break
+			}
+		}
+		graded.UserAnswerID = reply.AnswerID
+		graded.IsCorrect = graded.CorrectID != 0 && reply.AnswerID == graded.CorrectID
+	}
+
+	return graded
+}
+
+// awardPoints gives the full points for a correct answer, a wrong answer loses the penalty and a skipped
+// question only loses it when the quiz scores skipped questions like wrong ones.
+func awardPoints(question entity.Question, graded entity.SubmissionUserAnswer, unansweredPolicy string) float64 {
+	switch {
+	case graded.IsCorrect:
+		return question.Points
+	case graded.Skipped && unansweredPolicy != entity.UnansweredPenalty:
+		return 0
+	default:
+		return -question.Penalty
+	}
+}
+
+// scorePercentage stops at 0, raw points below 0 are still kept on the submission.
+func scorePercentage(points, maxPoints float64) float32 {
+	if maxPoints <= 0 || points <= 0 {
+		return 0
+	}
+	return float32(points / maxPoints * 100)
+}
+
+// matchesAcceptedAnswer compares a short text reply with one accepted answer, surrounding spaces never count.
+func matchesAcceptedAnswer(reply, accepted, mode string) bool {
+	reply = strings.TrimSpace(reply)
+	switch mode {
+	case entity.MatchCaseInsensitive:
+		return strings.EqualFold(reply, strings.TrimSpace(accepted))
+	case entity.MatchRegex:
+		re, err := helper.CompileAcceptedAnswer(accepted)
+		return err == nil && re.MatchString(reply)
+	default:
+		return reply == strings.TrimSpace(accepted)
+	}
+}
+
+// sameIDSet ignores order and repeated ids in picked.
+func sameIDSet(picked, correct []uint) bool {
+	chosen := make(map[uint]bool, len(picked))
+	for _, id := range picked {
+		chosen[id] = true
+	}
+	if len(chosen) != len(correct) {
+		return false
+	}
+	for _, id := range correct {
+		if !chosen[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSequence(got, expected []uint) bool {
+	if len(got) != len(expected) {
+		return false
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAllPairs needs every item paired exactly once with its own match, case and spaces are ignored.
+func matchesAllPairs(items []entity.Answer, pairs []dto.MatchPair) bool {
+	if len(pairs) != len(items) {
+		return false
+	}
+
+	expected := make(map[uint]string, len(items))
+	for _, item := range items {
+		expected[item.ID] = item.MatchText
+	}
+	for _, pair := range pairs {
+		match, ok := expected[pair.AnswerID]
+		if !ok || !strings.EqualFold(strings.TrimSpace(pair.Match), strings.TrimSpace(match)) {
+			return false
+		}
+		delete(expected, pair.AnswerID)
+	}
+	return true
+}
+
+func encodeJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IdentityRepository interface {
+	//oauth state
+	SaveState(state *entity.OAuthState) error
+	ConsumeState(stateHash string) (*entity.OAuthState, error)
+
+	//identity
+	GetIdentity(provider, subject string) (*entity.UserIdentity, error)
+	CreateIdentity(identity *entity.UserIdentity) error
+	CreateUserWithIdentity(user *entity.User, identity *entity.UserIdentity) error
+	IsUsernameTaken(username string) (bool, error)
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db}
+}
+
+func (r *identityRepository) SaveState(state *entity.OAuthState) error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&entity.OAuthState{}).Error; err != nil {
+		return err
+	}
+
+	return r.db.Create(state).Error
+}
+
+// ConsumeState deletes the state so an authorization response can only be used once.
+func (r *identityRepository) ConsumeState(stateHash string) (*entity.OAuthState, error) {
+	var state entity.OAuthState
+	if err := r.db.Where("state_hash = ?", stateHash).First(&state).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrInvalidOAuthState
+		}
+		return nil, err
+	}
+
+	deleted := r.db.Delete(&entity.OAuthState{}, state.ID)
+	if deleted.Error != nil {
+		return nil, deleted.Error
+	}
+	if deleted.RowsAffected == 0 || time.Now().After(state.ExpiresAt) {
+		return nil, helper.ErrInvalidOAuthState
+	}
+
+	return &state, nil
+}
+
+func (r *identityRepository) GetIdentity(provider, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func (r *identityRepository) CreateIdentity(identity *entity.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *identityRepository) CreateUserWithIdentity(user *entity.User, identity *entity.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *identityRepository) IsUsernameTaken(username string) (bool, error) {
+	var total int64
+	if err := r.db.Unscoped().Model(&entity.User{}).Where("username = ?", username).Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
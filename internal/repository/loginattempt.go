@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"api_quiz/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepository interface {
+	RecordFailedLogin(email, ip string) error
+	CountFailedByEmail(email string, since time.Time) (int64, *time.Time, error)
+	CountFailedByIP(ip string, since time.Time) (int64, error)
+	ClearFailedByEmail(email string) error
+}
+
+// failed logins older than this are never looked at again
+const failedLoginRetention = 24 * time.Hour
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+func (r *loginAttemptRepository) RecordFailedLogin(email, ip string) error {
+	if err := r.db.Where("created_at < ?", time.Now().Add(-failedLoginRetention)).Delete(&entity.FailedLogin{}).Error; err != nil {
+		return err
+	}
+
+	attempt := entity.FailedLogin{Email: email, IP: ip}
+	return r.db.Create(&attempt).Error
+}
+
+// CountFailedByEmail also returns the time of the latest failure, used for the progressive delay.
+func (r *loginAttemptRepository) CountFailedByEmail(email string, since time.Time) (int64, *time.Time, error) {
+	var result struct {
+		Total int64
+		Last  *time.Time
+	}
+	if err := r.db.Model(&entity.FailedLogin{}).
+		Select("COUNT(*) AS total, MAX(created_at) AS last").
+		Where("email = ? AND created_at >= ?", email, since).
+		Scan(&result).Error; err != nil {
+		return 0, nil, err
+	}
+
+	return result.Total, result.Last, nil
+}
+
+func (r *loginAttemptRepository) CountFailedByIP(ip string, since time.Time) (int64, error) {
+	var total int64
+	if err := r.db.Model(&entity.FailedLogin{}).Where("ip = ? AND created_at >= ?", ip, since).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *loginAttemptRepository) ClearFailedByEmail(email string) error {
+	return r.db.Where("email = ?", email).Delete(&entity.FailedLogin{}).Error
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OrganizationRepository interface {
+	CreateOrganization(org *entity.Organization, ownerId uint) error
+	SlugExists(slug string) (bool, error)
+	GetUserOrganizations(userId uint) ([]dto.OrganizationResponse, error)
+	GetOrganizationBySlug(slug string) (*entity.Organization, error)
+
+	//member
+	GetMembership(orgId, userId uint) (*entity.Membership, error)
+	GetMembers(orgId uint) ([]dto.MemberResponse, error)
+	AddMember(member *entity.Membership) error
+	UpdateMemberRole(orgId, userId uint, role string) error
+	RemoveMember(orgId, userId uint) error
+	CountOwners(orgId uint) (int64, error)
+
+	//active organization
+	SetActiveOrganization(userId, sessionId uint, orgId *uint) error
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db}
+}
+
+// inOrg scopes a query on a table with organization_id, 0 is the shared space of rows made outside any organization.
+func inOrg(orgId uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if orgId == 0 {
+			return db.Where("organization_id IS NULL")
+		}
+		return db.Where("organization_id = ?", orgId)
+	}
+}
+
+// quizzesInOrg is the subquery for tables that belong to an organization through their quiz.
+func quizzesInOrg(db *gorm.DB, orgId uint) *gorm.DB {
+	return db.Model(&entity.Quiz{}).Select("id").Scopes(inOrg(orgId))
+}
+
+func (r *organizationRepository) CreateOrganization(org *entity.Organization, ownerId uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&entity.Membership{
+			OrganizationID: org.ID,
+			UserID:         ownerId,
+			Role:           entity.OrgRoleOwner,
+		}).Error
+	})
+}
+
+func (r *organizationRepository) SlugExists(slug string) (bool, error) {
+	var total int64
+	if err := r.db.Model(&entity.Organization{}).Where("slug = ?", slug).Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+func (r *organizationRepository) GetUserOrganizations(userId uint) ([]dto.OrganizationResponse, error) {
+	var memberships []entity.Membership
+	if err := r.db.Preload("Organization").Where("user_id = ?", userId).Order("created_at").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.OrganizationResponse, len(memberships))
+	for i, m := range memberships {
+		response[i] = dto.OrganizationResponse{
+			ID:        m.Organization.ID,
+			Name:      m.Organization.Name,
+			Slug:      m.Organization.Slug,
+			Role:      m.Role,
+			CreatedAt: m.Organization.CreatedAt,
+		}
+	}
+
+	return response, nil
+}
+
+func (r *organizationRepository) GetOrganizationBySlug(slug string) (*entity.Organization, error) {
+	var org entity.Organization
+	if err := r.db.Where("slug = ?", slug).First(&org).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrOrgNotFound
+		}
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+func (r *organizationRepository) GetMembership(orgId, userId uint) (*entity.Membership, error) {
+	var member entity.Membership
+	if err := r.db.Where("organization_id = ? AND user_id = ?", orgId, userId).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrNotOrgMember
+		}
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (r *organizationRepository) GetMembers(orgId uint) ([]dto.MemberResponse, error) {
+	var memberships []entity.Membership
+	if err := r.db.Preload("User").Where("organization_id = ?", orgId).Order("created_at").Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.MemberResponse, 0, len(memberships))
+	for _, m := range memberships {
+		// the user is not preloaded when the account was deleted
+		if m.User.ID == 0 {
+			continue
+		}
+		response = append(response, dto.MemberResponse{
+			UserID:      m.User.ID,
+			Username:    m.User.Username,
+			DisplayName: m.User.DisplayName,
+			Email:       m.User.Email,
+			Role:        m.Role,
+			JoinedAt:    m.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+func (r *organizationRepository) AddMember(member *entity.Membership) error {
+	if _, err := r.GetMembership(member.OrganizationID, member.UserID); err == nil {
+		return helper.ErrAlreadyMember
+	} else if err != helper.ErrNotOrgMember {
+		return err
+	}
+
+	return r.db.Create(member).Error
+}
+
+func (r *organizationRepository) UpdateMemberRole(orgId, userId uint, role string) error {
+	updated := r.db.Model(&entity.Membership{}).Where("organization_id = ? AND user_id = ?", orgId, userId).Update("role", role)
+	if updated.Error != nil {
+		return updated.Error
+	}
+	if updated.RowsAffected == 0 {
+		if _, err := r.GetMembership(orgId, userId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveMember also moves the user's sessions out of the organization, drops them from its classes
+// and revokes the api keys that acted in it, so nothing they hold keeps working there.
+func (r *organizationRepository) RemoveMember(orgId, userId uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		deleted := tx.Where("organization_id = ? AND user_id = ?", orgId, userId).Delete(&entity.Membership{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		if deleted.RowsAffected == 0 {
+			return helper.ErrNotOrgMember
+		}
+
+		if err := tx.Model(&entity.User{}).Where("id = ? AND active_organization_id = ?", userId, orgId).
+			Update("active_organization_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Session{}).Where("user_id = ? AND organization_id = ?", userId, orgId).
+			Update("organization_id", nil).Error; err != nil {
+			return err
+		}
+		orgClasses := tx.Model(&entity.Class{}).Select("id").Scopes(inOrg(orgId))
+		if err := tx.Where("user_id = ? AND class_id IN (?)", userId, orgClasses).Delete(&entity.ClassMember{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&entity.APIKey{}).Where("user_id = ? AND organization_id = ? AND revoked_at IS NULL", userId, orgId).
+			Update("revoked_at", time.Now()).Error
+	})
+}
+
+func (r *organizationRepository) CountOwners(orgId uint) (int64, error) {
+	var total int64
+	if err := r.db.Model(&entity.Membership{}).Where("organization_id = ? AND role = ?", orgId, entity.OrgRoleOwner).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// SetActiveOrganization switches the session and remembers the choice for the next login.
+func (r *organizationRepository) SetActiveOrganization(userId, sessionId uint, orgId *uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.User{}).Where("id = ?", userId).Update("active_organization_id", orgId).Error; err != nil {
+			return err
+		}
+		if sessionId == 0 {
+			return nil
+		}
+
+		return tx.Model(&entity.Session{}).Where("id = ? AND user_id = ?", sessionId, userId).Update("organization_id", orgId).Error
+	})
+}
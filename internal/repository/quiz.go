@@ -4,33 +4,43 @@ import (
 	"api_quiz/dto"
 	"api_quiz/entity"
 	"api_quiz/utils/helper"
+	"math/rand/v2"
+	"sort"
 
 	"gorm.io/gorm"
 )
 
 type QuizRepository interface {
 	//quiz
-	GetAllQuiz() ([]dto.JustQuizResponse, error)
-	GetQuizById(quizId uint) (*dto.QuizResponseWithQS, error)
+	GetAllQuiz(orgId uint) ([]dto.JustQuizResponse, error)
+	GetQuizzesVisibleTo(userId, orgId uint) ([]dto.JustQuizResponse, error)
+	GetQuizzesByClass(classId uint) ([]dto.JustQuizResponse, error)
+	CanAccessQuiz(userId, quizId uint) (bool, error)
+	GetPublicQuizzes() ([]dto.JustQuizResponse, error)
+	IsPublicQuiz(quizId uint) (bool, error)
+	GetQuizById(orgId, quizId uint) (*dto.QuizResponseWithQS, error)
+	GetPublicQuizById(quizId uint) (*dto.QuizResponseWithQS, error)
 	CreateQuiz(input *dto.Quiz) (*dto.JustQuizResponse, error)
 	IsCreator(userId, quizId uint) (bool, error)
-	UpdateQuiz(input *dto.UpdatedQuiz, userId uint) (*dto.JustQuizResponse, error)
+	GetQuizCreator(orgId, quizId uint) (*uint, error)
+	GetRevealPolicy(quizId uint) (string, error)
+	UpdateQuiz(input *dto.UpdatedQuiz) (*dto.JustQuizResponse, error)
 	DeleteQuiz(quizId uint) error
 
 	//question
-	GetQuestionAnswerByQuizId(quizId uint) ([]dto.QuestionResponse, error)
-	GetQuestionById(questionId, quizId uint) (*dto.QuestionResponse, error)
+	GetQuestionAnswerByQuizId(orgId, quizId uint) ([]dto.QuestionResponse, error)
+	GetQuestionById(orgId, questionId, quizId uint) (*dto.QuestionResponse, error)
 	CreateQuestionAndAnswer(inputQuestion *dto.Question) (*dto.QuestionResponse, error)
 	UpdateQuestion(input *dto.QuestionUpdate) (*dto.JustQuestionResponse, error)
 	DeleteQuestion(quizId, questionId uint) error
+	ReorderQuestions(quizId uint, questionIds []uint) error
 
 	//answer
-	GetAnswerByQuestionId(questionId uint) ([]dto.AnswerResponse, error)
-	CheckTotalAnswer(questionId uint) (int64, error)
-	CheckTotalAnswerIscorrect(questionId uint) (int64, error)
+	GetQuizIdByQuestionId(orgId, questionId uint) (uint, error)
 	UpdateAnswer(input dto.Answer) ([]dto.AnswerResponse, error)
 	AddAnswer(input []dto.Answer) ([]dto.AnswerResponse, error)
 	DeleteAnswer(answerId, questionId uint) error
+	ReorderAnswers(questionId uint, answerIds []uint) ([]dto.AnswerResponse, error)
 }
 
 type quizRepository struct {
@@ -41,55 +51,108 @@ func NewQuizRepository(db *gorm.DB) QuizRepository {
 	return &quizRepository{db}
 }
 
+// byPosition keeps questions and answers in the order their creator set, id breaks the ties of rows made before positions existed.
+func byPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position").Order("id")
+}
+
 // quiz
-func (r *quizRepository) GetAllQuiz() ([]dto.JustQuizResponse, error) {
+// GetAllQuiz only lists the quizzes of the caller's organization.
+func (r *quizRepository) GetAllQuiz(orgId uint) ([]dto.JustQuizResponse, error) {
+	return r.findQuizzes(r.db.Model(&entity.Quiz{}).Scopes(inOrg(orgId)))
+}
+
+func (r *quizRepository) GetQuizzesVisibleTo(userId, orgId uint) ([]dto.JustQuizResponse, error) {
+	return r.findQuizzes(r.db.Model(&entity.Quiz{}).Scopes(inOrg(orgId), quizVisibleTo(r.db, userId)))
+}
+
+func (r *quizRepository) GetQuizzesByClass(classId uint) ([]dto.JustQuizResponse, error) {
+	return r.findQuizzes(r.db.Model(&entity.Quiz{}).Where("class_id = ?", classId))
+}
+
+// CanAccessQuiz is false when the quiz is restricted to a class the user does not teach or belong to.
+func (r *quizRepository) CanAccessQuiz(userId, quizId uint) (bool, error) {
+	var total int64
+	if err := r.db.Model(&entity.Quiz{}).Where("id = ?", quizId).Scopes(quizVisibleTo(r.db, userId)).Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+// quizzes restricted to a class are never public
+func (r *quizRepository) GetPublicQuizzes() ([]dto.JustQuizResponse, error) {
+	return r.findQuizzes(r.db.Model(&entity.Quiz{}).Where("is_public = ? AND class_id IS NULL", true))
+}
+
+func (r *quizRepository) findQuizzes(query *gorm.DB) ([]dto.JustQuizResponse, error) {
 	var quiz []entity.Quiz
-	if err := r.db.Select("id,title, creator_id").Find(&quiz).Error; err != nil {
+	if err := query.Select("id,title, creator_id, reveal_policy, is_public, organization_id, class_id, unanswered_policy").Order("id").Find(&quiz).Error; err != nil {
 		return nil, err
 	}
 
 	var response []dto.JustQuizResponse
 	for _, q := range quiz {
 		response = append(response, dto.JustQuizResponse{
-			ID:      q.ID,
-			Creator: q.CreatorID,
-			Title:   q.Title,
+			ID:               q.ID,
+			Creator:          q.CreatorID,
+			Title:            q.Title,
+			RevealPolicy:     q.RevealPolicy,
+			IsPublic:         q.IsPublic,
+			Organization:     q.OrganizationID,
+			Class:            q.ClassID,
+			UnansweredPolicy: q.UnansweredPolicy,
 		})
 	}
 
 	return response, nil
 }
-func (r *quizRepository) GetQuizById(quizId uint) (*dto.QuizResponseWithQS, error) {
+
+func (r *quizRepository) IsPublicQuiz(quizId uint) (bool, error) {
 	var quiz entity.Quiz
-	if err := r.db.Preload("Questions.Answers").Where("id = ?", quizId).First(&quiz).Error; err != nil {
+	if err := r.db.Select("id", "is_public", "class_id").Where("id = ?", quizId).First(&quiz).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, helper.ErrQuizNotFound
+		}
+		return false, err
+	}
+
+	return quiz.IsPublic && quiz.ClassID == nil, nil
+}
+func (r *quizRepository) GetQuizById(orgId, quizId uint) (*dto.QuizResponseWithQS, error) {
+	return r.findQuiz(r.db.Scopes(inOrg(orgId)).Where("id = ?", quizId))
+}
+
+// GetPublicQuizById is for guests, public quizzes can be played from outside their organization.
+func (r *quizRepository) GetPublicQuizById(quizId uint) (*dto.QuizResponseWithQS, error) {
+	return r.findQuiz(r.db.Where("id = ? AND is_public = ? AND class_id IS NULL", quizId, true))
+}
+
+func (r *quizRepository) findQuiz(query *gorm.DB) (*dto.QuizResponseWithQS, error) {
+	var quiz entity.Quiz
+	if err := query.Preload("Questions", byPosition).Preload("Questions.Answers", byPosition).First(&quiz).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrQuizNotFound
+		}
 		return nil, err
 	}
 
 	var questions []dto.QuestionResponse
 
 	for _, q := range quiz.Questions {
-		var answers []dto.AnswerResponse
-		for _, ans := range q.Answers {
-			answers = append(answers, dto.AnswerResponse{
-				ID:        ans.ID,
-				Text:      ans.Text,
-				IsCorrect: ans.IsCorrect,
-			})
-		}
-
-		questions = append(questions, dto.QuestionResponse{
-			ID:     q.ID,
-			QuizID: quizId,
-			Text:   q.Text,
-			Answer: answers,
-		})
+		questions = append(questions, toQuestionResponse(q))
 	}
 
 	response := dto.QuizResponseWithQS{
-		ID:       quiz.ID,
-		Creator:  *quiz.CreatorID,
-		Title:    quiz.Title,
-		Question: questions,
+		ID:               quiz.ID,
+		Creator:          quiz.CreatorID,
+		Title:            quiz.Title,
+		RevealPolicy:     quiz.RevealPolicy,
+		IsPublic:         quiz.IsPublic,
+		Organization:     quiz.OrganizationID,
+		Class:            quiz.ClassID,
+		UnansweredPolicy: quiz.UnansweredPolicy,
+		Question:         questions,
 	}
 
 	return &response, nil
@@ -98,8 +161,17 @@ func (r *quizRepository) GetQuizById(quizId uint) (*dto.QuizResponseWithQS, erro
 func (r *quizRepository) CreateQuiz(input *dto.Quiz) (*dto.JustQuizResponse, error) {
 
 	quiz := entity.Quiz{
-		Title:     input.Title,
-		CreatorID: &input.Creator,
+		Title:            input.Title,
+		CreatorID:        &input.Creator,
+		RevealPolicy:     input.RevealPolicy,
+		IsPublic:         input.IsPublic,
+		UnansweredPolicy: input.UnansweredPolicy,
+	}
+	if input.OrgID != 0 {
+		quiz.OrganizationID = &input.OrgID
+	}
+	if input.ClassID != nil && *input.ClassID != 0 {
+		quiz.ClassID = input.ClassID
 	}
 
 	result := r.db.Create(&quiz)
@@ -107,36 +179,97 @@ func (r *quizRepository) CreateQuiz(input *dto.Quiz) (*dto.JustQuizResponse, err
 		return nil, result.Error
 	}
 	response := dto.JustQuizResponse{
-		ID:      quiz.ID,
-		Creator: quiz.CreatorID,
-		Title:   quiz.Title,
+		ID:               quiz.ID,
+		Creator:          quiz.CreatorID,
+		Title:            quiz.Title,
+		RevealPolicy:     quiz.RevealPolicy,
+		IsPublic:         quiz.IsPublic,
+		Organization:     quiz.OrganizationID,
+		Class:            quiz.ClassID,
+		UnansweredPolicy: quiz.UnansweredPolicy,
 	}
 
 	return &response, nil
 }
 
 func (r *quizRepository) IsCreator(userId, quizId uint) (bool, error) {
-	var quiz entity.Quiz
-	if err := r.db.Where("id = ? AND creator_id = ?", quizId, userId).First(&quiz).Error; err != nil {
+	var total int64
+	if err := r.db.Model(&entity.Quiz{}).Where("id = ? AND creator_id = ?", quizId, userId).Count(&total).Error; err != nil {
 		return false, err
 	}
 
-	return true, nil
+	return total > 0, nil
 }
 
-func (r *quizRepository) UpdateQuiz(input *dto.UpdatedQuiz, userId uint) (*dto.JustQuizResponse, error) {
-	updated := r.db.Model(&entity.Quiz{}).Where("id = ?", input.ID).Update("title", input.Title)
-	if updated.Error != nil {
-		return nil, updated.Error
+// GetQuizCreator is the access check of every quiz change, quizzes of other organizations are not found.
+func (r *quizRepository) GetQuizCreator(orgId, quizId uint) (*uint, error) {
+	var quiz entity.Quiz
+	if err := r.db.Select("id", "creator_id").Scopes(inOrg(orgId)).Where("id = ?", quizId).First(&quiz).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrQuizNotFound
+		}
+		return nil, err
 	}
-	if updated.RowsAffected == 0 {
-		return nil, helper.ErrQuizNotFound
+
+	return quiz.CreatorID, nil
+}
+
+func (r *quizRepository) GetRevealPolicy(quizId uint) (string, error) {
+	var quiz entity.Quiz
+	if err := r.db.Select("id", "reveal_policy").Where("id = ?", quizId).First(&quiz).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", helper.ErrQuizNotFound
+		}
+		return "", err
+	}
+
+	return quiz.RevealPolicy, nil
+}
+
+func (r *quizRepository) UpdateQuiz(input *dto.UpdatedQuiz) (*dto.JustQuizResponse, error) {
+	fields := map[string]any{}
+	if input.Title != "" {
+		fields["title"] = input.Title
+	}
+	if input.RevealPolicy != "" {
+		fields["reveal_policy"] = input.RevealPolicy
+	}
+	if input.IsPublic != nil {
+		fields["is_public"] = *input.IsPublic
+	}
+	if input.UnansweredPolicy != "" {
+		fields["unanswered_policy"] = input.UnansweredPolicy
+	}
+	if input.ClassID != nil {
+		if *input.ClassID == 0 {
+			fields["class_id"] = nil
+		} else {
+			fields["class_id"] = *input.ClassID
+		}
+	}
+	if len(fields) > 0 {
+		if err := r.db.Model(&entity.Quiz{}).Where("id = ?", input.ID).Updates(fields).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var quiz entity.Quiz
+	if err := r.db.Select("id", "title", "creator_id", "reveal_policy", "is_public", "organization_id", "class_id", "unanswered_policy").Where("id = ?", input.ID).First(&quiz).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrQuizNotFound
+		}
+		return nil, err
 	}
 
 	response := dto.JustQuizResponse{
-		ID:      input.ID,
-		Creator: &userId,
-		Title:   input.Title,
+		ID:               quiz.ID,
+		Creator:          quiz.CreatorID,
+		Title:            quiz.Title,
+		RevealPolicy:     quiz.RevealPolicy,
+		IsPublic:         quiz.IsPublic,
+		Organization:     quiz.OrganizationID,
+		Class:            quiz.ClassID,
+		UnansweredPolicy: quiz.UnansweredPolicy,
 	}
 
 	return &response, nil
@@ -157,91 +290,76 @@ func (r *quizRepository) DeleteQuiz(quizId uint) error {
 }
 
 // question of quizz
-func (r *quizRepository) GetQuestionAnswerByQuizId(quizId uint) ([]dto.QuestionResponse, error) {
+func (r *quizRepository) GetQuestionAnswerByQuizId(orgId, quizId uint) ([]dto.QuestionResponse, error) {
 	var question []entity.Question
-	if err := r.db.Model(&entity.Question{}).Preload("Answers").Where("quiz_id = ?", quizId).Find(&question).Error; err != nil {
+	if err := r.db.Model(&entity.Question{}).Preload("Answers", byPosition).Scopes(byPosition).
+		Where("quiz_id = ? AND quiz_id IN (?)", quizId, quizzesInOrg(r.db, orgId)).Find(&question).Error; err != nil {
 		return nil, err
 	}
 
 	questionResponse := make([]dto.QuestionResponse, len(question))
 	for i, q := range question {
-		answers := make([]dto.AnswerResponse, len(q.Answers))
-		for i, ans := range q.Answers {
-			answers[i] = dto.AnswerResponse{
-				ID:         ans.ID,
-				QuestionID: ans.QuestionID,
-				Text:       ans.Text,
-				IsCorrect:  ans.IsCorrect,
-			}
-		}
-		questionResponse[i] = dto.QuestionResponse{
-			ID:     q.ID,
-			QuizID: quizId,
-			Text:   q.Text,
-			Answer: answers,
-		}
+		questionResponse[i] = toQuestionResponse(q)
 	}
 
 	return questionResponse, nil
 }
 
-func (r *quizRepository) GetQuestionById(questionId, quizId uint) (*dto.QuestionResponse, error) {
+func (r *quizRepository) GetQuestionById(orgId, questionId, quizId uint) (*dto.QuestionResponse, error) {
 	var question entity.Question
-	if err := r.db.Model(&entity.Question{}).Preload("Answers").Where("id = ? AND quiz_id = ? ", questionId, quizId).First(&question).Error; err != nil {
-		return nil, err
-	}
-
-	responseAnswer := make([]dto.AnswerResponse, len(question.Answers))
-	for i, ans := range question.Answers {
-		responseAnswer[i] = dto.AnswerResponse{
-			ID:         ans.ID,
-			QuestionID: ans.QuestionID,
-			Text:       ans.Text,
-			IsCorrect:  ans.IsCorrect,
+	if err := r.db.Model(&entity.Question{}).Preload("Answers", byPosition).
+		Where("id = ? AND quiz_id = ? AND quiz_id IN (?)", questionId, quizId, quizzesInOrg(r.db, orgId)).First(&question).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrQuestionNotFound
 		}
+		return nil, err
 	}
 
-	response := dto.QuestionResponse{
-		ID:     question.ID,
-		QuizID: question.QuizID,
-		Text:   question.Text,
-		Answer: responseAnswer,
-	}
-
+	response := toQuestionResponse(question)
 	return &response, nil
 }
 func (r *quizRepository) CreateQuestionAndAnswer(inputQuestion *dto.Question) (*dto.QuestionResponse, error) {
 	question := entity.Question{
-		QuizID: inputQuestion.QuizID,
-		Text:   inputQuestion.Text,
+		QuizID:    inputQuestion.QuizID,
+		Text:      inputQuestion.Text,
+		Type:      inputQuestion.Type,
+		MatchMode: inputQuestion.MatchMode,
+		Tolerance: inputQuestion.Tolerance,
+		Points:    inputQuestion.Points,
+		Penalty:   inputQuestion.Penalty,
 	}
 	tx := r.db.Begin()
 
+	// new questions go after the last one, answers keep the order they were sent in
+	last, err := lastPosition(tx, &entity.Question{}, "quiz_id", question.QuizID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	question.Position = last + 1
+
 	if err := tx.Create(&question).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
 	answers := make([]entity.Answer, 0, len(inputQuestion.Answers))
-	responseAnswers := make([]dto.AnswerResponse, 0, len(inputQuestion.Answers))
-
-	for _, ans := range inputQuestion.Answers {
-		answerEntity := entity.Answer{
-			QuestionID: question.ID,
-			Text:       ans.Text,
-			IsCorrect:  ans.IsCorrect,
-		}
-
-		answers = append(answers, answerEntity)
-
-		responseAnswers = append(responseAnswers, dto.AnswerResponse{
-			ID:         answerEntity.ID,
-			QuestionID: answerEntity.QuestionID,
-			Text:       answerEntity.Text,
-			IsCorrect:  answerEntity.IsCorrect,
+	for i, ans := range inputQuestion.Answers {
+		answers = append(answers, entity.Answer{
+			QuestionID:  question.ID,
+			Text:        ans.Text,
+			IsCorrect:   ans.IsCorrect,
+			Position:    i + 1,
+			CorrectRank: ans.Rank,
+			MatchText:   ans.MatchText,
 		})
 	}
 
+	// ordering items are mostly sent in the correct sequence, inserting them shuffled keeps their ids from following it
+	if question.Type == entity.QuestionOrdering {
+		rand.Shuffle(len(answers), func(i, j int) { answers[i], answers[j] = answers[j], answers[i] })
+	}
+
 	if err := tx.Create(&answers).Error; err != nil {
 		tx.Rollback()
 		return nil, err
@@ -249,13 +367,9 @@ func (r *quizRepository) CreateQuestionAndAnswer(inputQuestion *dto.Question) (*
 
 	tx.Commit()
 
-	response := dto.QuestionResponse{
-		ID:     question.ID,
-		QuizID: question.QuizID,
-		Text:   question.Text,
-		Answer: responseAnswers,
-	}
-
+	sort.Slice(answers, func(i, j int) bool { return answers[i].Position < answers[j].Position })
+	question.Answers = answers
+	response := toQuestionResponse(question)
 	return &response, nil
 
 }
@@ -273,13 +387,15 @@ func (r *quizRepository) DeleteQuestion(quizId, questionId uint) error {
 }
 
 func (r *quizRepository) UpdateQuestion(input *dto.QuestionUpdate) (*dto.JustQuestionResponse, error) {
-	question := entity.Question{
-		ID:     input.ID,
-		QuizID: input.QuizID,
-		Text:   input.Text,
-	}
-
-	updated := r.db.Model(&entity.Question{}).Where("id = ? AND quiz_id = ? ", input.ID, input.QuizID).Updates(&question)
+	updated := r.db.Model(&entity.Question{}).Where("id = ? AND quiz_id = ? ", input.ID, input.QuizID).
+		Select("text", "match_mode", "tolerance", "points", "penalty").
+		Updates(entity.Question{
+			Text:      input.Text,
+			MatchMode: input.MatchMode,
+			Tolerance: *input.Tolerance,
+			Points:    *input.Points,
+			Penalty:   *input.Penalty,
+		})
 	if updated.Error != nil {
 		return nil, updated.Error
 	}
@@ -287,180 +403,201 @@ func (r *quizRepository) UpdateQuestion(input *dto.QuestionUpdate) (*dto.JustQue
 		return nil, helper.ErrQuestionNotFound
 	}
 
+	var question entity.Question
+	if err := r.db.Where("id = ?", input.ID).First(&question).Error; err != nil {
+		return nil, err
+	}
+
 	response := dto.JustQuestionResponse{
-		ID:     question.ID,
-		QuizID: question.QuizID,
-		Text:   question.Text,
+		ID:        question.ID,
+		QuizID:    question.QuizID,
+		Position:  question.Position,
+		Text:      question.Text,
+		Type:      question.Type,
+		MatchMode: question.MatchMode,
+		Tolerance: question.Tolerance,
+		Points:    question.Points,
+		Penalty:   question.Penalty,
 	}
 	return &response, nil
 }
 
 // answer of quizz
-func (r *quizRepository) GetAnswerByQuestionId(questionId uint) ([]dto.AnswerResponse, error) {
-	var answer []entity.Answer
-	if err := r.db.Model(&entity.Answer{}).Where("question_id = ?", questionId).Find(&answer).Error; err != nil {
-		return nil, err
-	}
-
-	response := make([]dto.AnswerResponse, len(answer))
-	for i, ans := range answer {
-		response[i] = dto.AnswerResponse{
-			ID:         ans.ID,
-			QuestionID: ans.QuestionID,
-			Text:       ans.Text,
-			IsCorrect:  ans.IsCorrect,
+func (r *quizRepository) GetQuizIdByQuestionId(orgId, questionId uint) (uint, error) {
+	var question entity.Question
+	if err := r.db.Select("id", "quiz_id").Where("id = ? AND quiz_id IN (?)", questionId, quizzesInOrg(r.db, orgId)).First(&question).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, helper.ErrQuestionNotFound
 		}
+		return 0, err
 	}
-	return response, nil
+
+	return question.QuizID, nil
 }
 
+// the answer set is validated against the question type in the usecase before it is written
 func (r *quizRepository) UpdateAnswer(input dto.Answer) ([]dto.AnswerResponse, error) {
-	tx := r.db.Begin()
-
-	if err := tx.Model(&entity.Answer{}).
+	updated := r.db.Model(&entity.Answer{}).
 		Where("id = ? AND question_id = ?", input.ID, input.QuestionID).
-		Select("text", "is_correct").
+		Select("text", "is_correct", "correct_rank", "match_text").
 		Updates(entity.Answer{
-			Text:      input.Text,
-			IsCorrect: input.IsCorrect,
-		}).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	correctAnswers, err := r.CheckTotalAnswerIscorrect(input.QuestionID)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-	if correctAnswers != 1 {
-		tx.Rollback()
-		return nil, helper.ErrCorrectAnswer
-	}
-
-	tx.Commit()
-
-	var answers []entity.Answer
-	if err := r.db.Where("question_id = ?", input.QuestionID).Find(&answers).Error; err != nil {
-		return nil, err
-	}
-
-	response := make([]dto.AnswerResponse, 0, len(answers))
-	for _, ans := range answers {
-		response = append(response, dto.AnswerResponse{
-			ID:         ans.ID,
-			QuestionID: ans.QuestionID,
-			Text:       ans.Text,
-			IsCorrect:  ans.IsCorrect,
+			Text:        input.Text,
+			IsCorrect:   input.IsCorrect,
+			CorrectRank: input.Rank,
+			MatchText:   input.MatchText,
 		})
+	if updated.Error != nil {
+		return nil, updated.Error
+	}
+	if updated.RowsAffected == 0 {
+		return nil, helper.ErrAnswerNotFound
 	}
 
-	return response, nil
+	return r.findAnswers(input.QuestionID)
 }
 
 func (r *quizRepository) AddAnswer(input []dto.Answer) ([]dto.AnswerResponse, error) {
-	tx := r.db.Begin()
 	questionID := input[0].QuestionID
 
-	totalAnswers, err := r.CheckTotalAnswer(questionID)
-	if err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		last, err := lastPosition(tx, &entity.Answer{}, "question_id", questionID)
+		if err != nil {
+			return err
+		}
 
-	totalCorrect, err := r.CheckTotalAnswerIscorrect(questionID)
+		answers := make([]entity.Answer, len(input))
+		for i, ans := range input {
+			answers[i] = entity.Answer{
+				QuestionID:  ans.QuestionID,
+				Text:        ans.Text,
+				IsCorrect:   ans.IsCorrect,
+				Position:    last + i + 1,
+				CorrectRank: ans.Rank,
+				MatchText:   ans.MatchText,
+			}
+		}
+
+		return tx.Create(&answers).Error
+	})
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	newCorrectCount := 0
-	for _, ans := range input {
-		if ans.IsCorrect {
-			newCorrectCount++
-		}
-	}
+	return r.findAnswers(questionID)
+}
 
-	if totalAnswers+int64(len(input)) > 5 {
-		tx.Rollback()
-		return nil, helper.ErrToomuchAnswer
+func (r *quizRepository) DeleteAnswer(answerId, questionId uint) error {
+	deleted := r.db.Where("id = ? AND  question_id = ?", answerId, questionId).Delete(&entity.Answer{})
+	if deleted.Error != nil {
+		return deleted.Error
 	}
-
-	if totalCorrect+int64(newCorrectCount) != 1 {
-		tx.Rollback()
-		return nil, helper.ErrCorrectAnswer
+	if deleted.RowsAffected == 0 {
+		return helper.ErrAnswerNotFound
 	}
 
-	answers := make([]entity.Answer, len(input))
-	for i, ans := range input {
-		answers[i] = entity.Answer{
-			QuestionID: ans.QuestionID,
-			Text:       ans.Text,
-			IsCorrect:  ans.IsCorrect,
-		}
-	}
+	return nil
+}
 
-	if err := tx.Create(&answers).Error; err != nil {
-		tx.Rollback()
+func (r *quizRepository) ReorderQuestions(quizId uint, questionIds []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return reorder(tx, &entity.Question{}, "quiz_id", quizId, questionIds, helper.ErrQuestionOrder)
+	})
+}
+
+func (r *quizRepository) ReorderAnswers(questionId uint, answerIds []uint) ([]dto.AnswerResponse, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return reorder(tx, &entity.Answer{}, "question_id", questionId, answerIds, helper.ErrAnswerOrder)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	tx.Commit()
+	return r.findAnswers(questionId)
+}
 
-	var ResponseAnswers []entity.Answer
-	if err := r.db.Where("question_id = ?", questionID).Find(&ResponseAnswers).Error; err != nil {
-		return nil, err
+// reorder numbers the rows from 1 in the order of ids, ids must hold every row under the parent once.
+func reorder(tx *gorm.DB, model any, parentColumn string, parentId uint, ids []uint, errInvalid error) error {
+	var current []uint
+	if err := tx.Model(model).Where(parentColumn+" = ?", parentId).Pluck("id", &current).Error; err != nil {
+		return err
+	}
+	if len(ids) != len(current) || !sameIDSet(ids, current) {
+		return errInvalid
 	}
 
-	response := make([]dto.AnswerResponse, len(ResponseAnswers))
-	for i, ans := range ResponseAnswers {
-		response[i] = dto.AnswerResponse{
-			ID:         ans.ID,
-			QuestionID: ans.QuestionID,
-			Text:       ans.Text,
-			IsCorrect:  ans.IsCorrect,
+	for i, id := range ids {
+		if err := tx.Model(model).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+			return err
 		}
 	}
 
-	return response, nil
+	return nil
 }
 
-func (r *quizRepository) DeleteAnswer(answerId, questionId uint) error {
-	tx := r.db.Begin()
-
-	if err := tx.Where("id = ? AND  question_id = ?", answerId, questionId).Delete(&entity.Answer{}).Error; err != nil {
-		tx.Rollback()
-		return err
+func lastPosition(tx *gorm.DB, model any, parentColumn string, parentId uint) (int, error) {
+	var last int
+	if err := tx.Model(model).Where(parentColumn+" = ?", parentId).Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+		return 0, err
 	}
+	return last, nil
+}
 
-	totalAnswers, err := r.CheckTotalAnswer(questionId)
-	if err != nil {
-		tx.Rollback()
-		return err
+func (r *quizRepository) findAnswers(questionId uint) ([]dto.AnswerResponse, error) {
+	var answers []entity.Answer
+	if err := r.db.Where("question_id = ?", questionId).Scopes(byPosition).Find(&answers).Error; err != nil {
+		return nil, err
 	}
 
-	if totalAnswers <= 2 {
-		tx.Rollback()
-		return helper.ErrAnswerNotEnough
+	response := make([]dto.AnswerResponse, len(answers))
+	for i, ans := range answers {
+		response[i] = toAnswerResponse(ans)
 	}
 
-	return tx.Commit().Error
+	return response, nil
 }
 
-func (r *quizRepository) CheckTotalAnswer(questionId uint) (int64, error) {
-	var total int64
-	if err := r.db.Model(&entity.Answer{}).Where("question_id = ?", questionId).Count(&total).Error; err != nil {
-		return 0, err
+func toQuestionResponse(q entity.Question) dto.QuestionResponse {
+	answers := make([]dto.AnswerResponse, len(q.Answers))
+	for i, ans := range q.Answers {
+		answers[i] = toAnswerResponse(ans)
 	}
 
-	return total, nil
+	response := dto.QuestionResponse{
+		ID:       q.ID,
+		QuizID:   q.QuizID,
+		Position: q.Position,
+		Text:     q.Text,
+		Type:     q.Type,
+		Points:   q.Points,
+		Penalty:  q.Penalty,
+		Answer:   answers,
+	}
+	switch q.Type {
+	case entity.QuestionShortText:
+		response.MatchMode = q.MatchMode
+	case entity.QuestionNumeric:
+		response.Tolerance = q.Tolerance
+	}
+
+	return response
 }
 
-func (r *quizRepository) CheckTotalAnswerIscorrect(questionId uint) (int64, error) {
-	var total int64
-	if err := r.db.Model(&entity.Answer{}).Where("question_id = ? AND is_correct = ?", questionId, true).Count(&total).Error; err != nil {
-		return 0, err
+func toAnswerResponse(ans entity.Answer) dto.AnswerResponse {
+	isCorrect := ans.IsCorrect
+	response := dto.AnswerResponse{
+		ID:         ans.ID,
+		QuestionID: ans.QuestionID,
+		Position:   ans.Position,
+		Text:       ans.Text,
+		IsCorrect:  &isCorrect,
 	}
-
-	return total, nil
+	if ans.CorrectRank != 0 {
+		rank := ans.CorrectRank
+		response.Rank = &rank
+	}
+	if ans.MatchText != "" {
+		matchText := ans.MatchText
+		response.MatchText = &matchText
+	}
+	return response
 }
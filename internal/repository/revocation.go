@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"api_quiz/entity"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevocationRepository keeps track of access tokens that must be rejected before they expire.
+type RevocationRepository interface {
+	RevokeToken(jti string, expiresAt time.Time) error
+	IsTokenRevoked(jti string) (bool, error)
+	RevokeUser(userId uint) error
+	IsUserRevoked(userId uint, issuedAt time.Time) (bool, error)
+}
+
+type revocationRepository struct {
+	db *gorm.DB
+}
+
+func NewRevocationRepository(db *gorm.DB) RevocationRepository {
+	return &revocationRepository{db}
+}
+
+func (r *revocationRepository) RevokeToken(jti string, expiresAt time.Time) error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&entity.RevokedToken{}).Error; err != nil {
+		return err
+	}
+
+	token := entity.RevokedToken{JTI: jti, ExpiresAt: expiresAt}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&token).Error
+}
+
+func (r *revocationRepository) IsTokenRevoked(jti string) (bool, error) {
+	var total int64
+	if err := r.db.Model(&entity.RevokedToken{}).Where("jti = ?", jti).Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+func (r *revocationRepository) RevokeUser(userId uint) error {
+	revocation := entity.UserRevocation{UserID: userId, RevokedAt: revokedNow()}
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&revocation).Error
+}
+
+func (r *revocationRepository) IsUserRevoked(userId uint, issuedAt time.Time) (bool, error) {
+	var revocation entity.UserRevocation
+	if err := r.db.Where("user_id = ?", userId).First(&revocation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return issuedAt.Before(revocation.RevokedAt), nil
+}
+
+// revokedNow is rounded up to the microseconds the column keeps, so a token issued just before
+// the revoke can never look newer than it
+func revokedNow() time.Time {
+	return time.Now().Truncate(time.Microsecond).Add(time.Microsecond)
+}
+
+// in memory store, only usable when the api runs as a single instance
+type memoryRevocationRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[uint]time.Time
+}
+
+func NewMemoryRevocationRepository() RevocationRepository {
+	return &memoryRevocationRepository{
+		tokens: make(map[string]time.Time),
+		users:  make(map[uint]time.Time),
+	}
+}
+
+func (r *memoryRevocationRepository) RevokeToken(jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for k, exp := range r.tokens {
+		if exp.Before(now) {
+			delete(r.tokens, k)
+		}
+	}
+	r.tokens[jti] = expiresAt
+	return nil
+}
+
+func (r *memoryRevocationRepository) IsTokenRevoked(jti string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.tokens[jti]
+	return ok, nil
+}
+
+func (r *memoryRevocationRepository) RevokeUser(userId uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[userId] = time.Now()
+	return nil
+}
+
+func (r *memoryRevocationRepository) IsUserRevoked(userId uint, issuedAt time.Time) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revokedAt, ok := r.users[userId]
+	if !ok {
+		return false, nil
+	}
+	return issuedAt.Before(revokedAt), nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type RosterRepository interface {
+	ImportRoster(orgId uint, seeAll bool, entries []RosterEntry) error
+}
+
+// RosterEntry is one valid csv row, User, Created, Hidden and Err are filled in by ImportRoster.
+// Hidden marks an account the importer may not see, nothing is written for it and it has to join on its own.
+type RosterEntry struct {
+	Email    string
+	Username string
+	ClassID  uint
+	Password string
+	Tokens   []entity.UserToken
+
+	User    *entity.User
+	Created bool
+	Hidden  bool
+	Err     error
+}
+
+type rosterRepository struct {
+	db *gorm.DB
+}
+
+func NewRosterRepository(db *gorm.DB) RosterRepository {
+	return &rosterRepository{db}
+}
+
+// ImportRoster writes every entry in one transaction, a failing row is rolled back to its savepoint
+// and reported on the entry so the other rows still go through. seeAll is for admins, they may put
+// any account in the organization and class, everyone else only accounts already in the organization.
+func (r *rosterRepository) ImportRoster(orgId uint, seeAll bool, entries []RosterEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range entries {
+			savepoint := fmt.Sprintf("roster_row_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+
+			if err := importRosterEntry(tx, orgId, seeAll, &entries[i]); err != nil {
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return err
+				}
+				entries[i].User = nil
+				entries[i].Created = false
+				entries[i].Hidden = false
+				entries[i].Err = err
+			}
+		}
+
+		return nil
+	})
+}
+
+// importRosterEntry creates the account when the email is new, existing accounts are only put in the organization and class.
+func importRosterEntry(tx *gorm.DB, orgId uint, seeAll bool, entry *RosterEntry) error {
+	var user entity.User
+	err := tx.Where("email = ?", entry.Email).First(&user).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		// deleted accounts still hold their email and username until they are purged
+		var taken int64
+		if err := tx.Unscoped().Model(&entity.User{}).Where("email = ?", entry.Email).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			if !seeAll {
+				entry.Hidden = true
+				return nil
+			}
+			return helper.ErrEmailTaken
+		}
+		if err := tx.Unscoped().Model(&entity.User{}).Where("username = ?", entry.Username).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return helper.ErrUsernameTaken
+		}
+
+		user = entity.User{
+			Email:    entry.Email,
+			Username: entry.Username,
+			Password: entry.Password,
+			Role:     entity.RoleStudent,
+		}
+		if orgId != 0 {
+			user.ActiveOrganizationID = &orgId
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+
+		for i := range entry.Tokens {
+			entry.Tokens[i].UserID = user.ID
+		}
+		if len(entry.Tokens) > 0 {
+			if err := tx.Create(&entry.Tokens).Error; err != nil {
+				return err
+			}
+		}
+		entry.Created = true
+	} else if !seeAll {
+		visible := false
+		if orgId != 0 {
+			var member int64
+			if err := tx.Model(&entity.Membership{}).Where("organization_id = ? AND user_id = ?", orgId, user.ID).Count(&member).Error; err != nil {
+				return err
+			}
+			visible = member > 0
+		}
+		if !visible {
+			entry.User = &user
+			entry.Hidden = true
+			return nil
+		}
+	}
+
+	if orgId != 0 {
+		var member int64
+		if err := tx.Model(&entity.Membership{}).Where("organization_id = ? AND user_id = ?", orgId, user.ID).Count(&member).Error; err != nil {
+			return err
+		}
+		if member == 0 {
+			if err := tx.Create(&entity.Membership{OrganizationID: orgId, UserID: user.ID, Role: entity.OrgRoleMember}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if entry.ClassID != 0 {
+		var member int64
+		if err := tx.Model(&entity.ClassMember{}).Where("class_id = ? AND user_id = ?", entry.ClassID, user.ID).Count(&member).Error; err != nil {
+			return err
+		}
+		if member == 0 {
+			if err := tx.Create(&entity.ClassMember{ClassID: entry.ClassID, UserID: user.ID}).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	entry.User = &user
+	return nil
+}
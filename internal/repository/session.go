@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	CreateSession(session *entity.Session) error
+	GetSessionById(id uint) (*entity.Session, error)
+	GetSessionByFamily(familyId string) (*entity.Session, error)
+	GetActiveSessions(userId uint) ([]entity.Session, error)
+	TouchSession(id uint, ip, userAgent string, at time.Time) error
+
+	//revoke
+	RevokeSession(userId, id uint) (*entity.Session, error)
+	RevokeOtherSessions(userId, keepId uint) ([]entity.Session, error)
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db}
+}
+
+func (r *sessionRepository) CreateSession(session *entity.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) GetSessionById(id uint) (*entity.Session, error) {
+	var session entity.Session
+	if err := r.db.Where("id = ?", id).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *sessionRepository) GetSessionByFamily(familyId string) (*entity.Session, error) {
+	var session entity.Session
+	if err := r.db.Where("family_id = ?", familyId).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetActiveSessions only lists sessions that still hold a usable refresh token, so logouts,
+// reuse detection and revoke-all hide them without touching the session rows.
+func (r *sessionRepository) GetActiveSessions(userId uint) ([]entity.Session, error) {
+	usable := r.db.Model(&entity.RefreshToken{}).Select("family_id").
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now())
+
+	var sessions []entity.Session
+	if err := r.db.Where("user_id = ? AND revoked_at IS NULL AND family_id IN (?)", userId, usable).
+		Order("last_seen_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) TouchSession(id uint, ip, userAgent string, at time.Time) error {
+	fields := map[string]any{"last_seen_at": at}
+	if ip != "" {
+		fields["ip"] = ip
+	}
+	if userAgent != "" {
+		if len(userAgent) > 255 {
+			userAgent = userAgent[:255]
+		}
+		fields["user_agent"] = userAgent
+	}
+
+	return r.db.Model(&entity.Session{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *sessionRepository) RevokeSession(userId, id uint) (*entity.Session, error) {
+	var session entity.Session
+	if err := r.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userId).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := r.db.Model(&session).Update("revoked_at", now).Error; err != nil {
+		return nil, err
+	}
+
+	session.RevokedAt = &now
+	return &session, nil
+}
+
+func (r *sessionRepository) RevokeOtherSessions(userId, keepId uint) ([]entity.Session, error) {
+	var sessions []entity.Session
+	if err := r.db.Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userId, keepId).Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return sessions, nil
+	}
+
+	if err := r.db.Model(&entity.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userId, keepId).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
@@ -4,6 +4,7 @@ import (
 	"api_quiz/dto"
 	"api_quiz/entity"
 	"api_quiz/utils/helper"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -11,10 +12,16 @@ import (
 )
 
 type SubmissionRepository interface {
-	GetAllSubmission() ([]dto.JustSubmissionResponse, error)
-	GetSubmissionById(submissionId uint) (*dto.SubmissionResponse, error)
+	GetAllSubmission(orgId uint) ([]dto.JustSubmissionResponse, error)
+	GetSubmissionsByUser(userId, orgId uint) ([]dto.JustSubmissionResponse, error)
+	GetSubmissionsByQuiz(quizId uint) ([]dto.JustSubmissionResponse, error)
+	GetSubmissionsByClass(classId uint) ([]dto.JustSubmissionResponse, error)
+	GetSubmissionsVisibleTo(userId, orgId uint) ([]dto.JustSubmissionResponse, error)
+	GetSubmissionsByGuest(guestId string) ([]dto.JustSubmissionResponse, error)
+	ClaimGuestSubmissions(guestId string, userId uint) (int64, error)
+	GetSubmissionById(orgId, submissionId uint) (*dto.SubmissionResponse, error)
 	CreateSubmission(input *dto.Submission) (*dto.SubmissionResponse, error)
-	GetQuizIdFromSubmisionId(submisionId uint) (uint, error)
+	GetQuizIdFromSubmisionId(orgId, submisionId uint) (uint, error)
 	UpdateSubmission(input *dto.SubmissionUpdate) (*dto.JustSubmissionResponse, error)
 	DeleteSubmission(submissionId uint) error
 }
@@ -27,9 +34,51 @@ func NewSubmissionRepository(db *gorm.DB) SubmissionRepository {
 	return &submissionRepository{db}
 }
 
-func (r *submissionRepository) GetAllSubmission() ([]dto.JustSubmissionResponse, error) {
+// submissions belong to the organization of their quiz
+func (r *submissionRepository) GetAllSubmission(orgId uint) ([]dto.JustSubmissionResponse, error) {
+	return r.findSubmissions(r.db.Model(&entity.Submission{}).Where("quiz_id IN (?)", quizzesInOrg(r.db, orgId)))
+}
+
+func (r *submissionRepository) GetSubmissionsByUser(userId, orgId uint) ([]dto.JustSubmissionResponse, error) {
+	return r.findSubmissions(r.db.Model(&entity.Submission{}).Where("user_id = ? AND quiz_id IN (?)", userId, quizzesInOrg(r.db, orgId)))
+}
+
+func (r *submissionRepository) GetSubmissionsByQuiz(quizId uint) ([]dto.JustSubmissionResponse, error) {
+	return r.findSubmissions(r.db.Model(&entity.Submission{}).Where("quiz_id = ?", quizId))
+}
+
+// GetSubmissionsByClass returns the attempts on every quiz restricted to the class.
+func (r *submissionRepository) GetSubmissionsByClass(classId uint) ([]dto.JustSubmissionResponse, error) {
+	classQuiz := r.db.Model(&entity.Quiz{}).Select("id").Where("class_id = ?", classId)
+	return r.findSubmissions(r.db.Model(&entity.Submission{}).Where("quiz_id IN (?)", classQuiz))
+}
+
+// GetSubmissionsVisibleTo returns the user's own attempts plus every attempt on quizzes they created.
+func (r *submissionRepository) GetSubmissionsVisibleTo(userId, orgId uint) ([]dto.JustSubmissionResponse, error) {
+	createdQuiz := quizzesInOrg(r.db, orgId).Where("creator_id = ?", userId)
+	return r.findSubmissions(r.db.Model(&entity.Submission{}).
+		Where("(user_id = ? AND quiz_id IN (?)) OR quiz_id IN (?)", userId, quizzesInOrg(r.db, orgId), createdQuiz))
+}
+
+func (r *submissionRepository) GetSubmissionsByGuest(guestId string) ([]dto.JustSubmissionResponse, error) {
+	return r.findSubmissions(r.db.Model(&entity.Submission{}).Where("guest_id = ? AND user_id IS NULL", guestId))
+}
+
+// ClaimGuestSubmissions hands every unclaimed attempt of the guest over to the user.
+func (r *submissionRepository) ClaimGuestSubmissions(guestId string, userId uint) (int64, error) {
+	claimed := r.db.Model(&entity.Submission{}).
+		Where("guest_id = ? AND user_id IS NULL", guestId).
+		Updates(map[string]any{"user_id": userId, "guest_id": nil})
+	if claimed.Error != nil {
+		return 0, claimed.Error
+	}
+
+	return claimed.RowsAffected, nil
+}
+
+func (r *submissionRepository) findSubmissions(query *gorm.DB) ([]dto.JustSubmissionResponse, error) {
 	var submission []entity.Submission
-	if err := r.db.Model(&entity.Submission{}).Find(&submission).Error; err != nil {
+	if err := query.Order("created_at DESC").Find(&submission).Error; err != nil {
 		return nil, err
 	}
 
@@ -38,7 +87,9 @@ func (r *submissionRepository) GetAllSubmission() ([]dto.JustSubmissionResponse,
 		response[i] = dto.JustSubmissionResponse{
 			ID:        s.ID,
 			QuizID:    s.QuizID,
-			UserID:    *s.UserID,
+			UserID:    ownerId(s.UserID),
+			Points:    s.Points,
+			MaxPoints: s.MaxPoints,
 			Score:     s.Score,
 			CreatedAt: s.CreatedAt,
 			UpdatedAt: s.UpdatedAt,
@@ -48,27 +99,27 @@ func (r *submissionRepository) GetAllSubmission() ([]dto.JustSubmissionResponse,
 	return response, nil
 }
 
-func (r *submissionRepository) GetSubmissionById(submissionId uint) (*dto.SubmissionResponse, error) {
+func (r *submissionRepository) GetSubmissionById(orgId, submissionId uint) (*dto.SubmissionResponse, error) {
 	var submission entity.Submission
 
-	if err := r.db.Preload("Answers").Where("id = ?", submissionId).First(&submission).Error; err != nil {
+	if err := r.db.Preload("Answers").Where("id = ? AND quiz_id IN (?)", submissionId, quizzesInOrg(r.db, orgId)).First(&submission).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrSubmissionNotFound
+		}
 		return nil, err
 	}
 
 	answers := make([]dto.SubmissionAnswerResponse, len(submission.Answers))
 	for i, ans := range submission.Answers {
-		answers[i] = dto.SubmissionAnswerResponse{
-			QuestionID:    ans.QuestionID,
-			AnswerUser:    ans.UserAnswerID,
-			CorrectAnswer: ans.CorrectID,
-			IsCorrect:     ans.IsCorrect,
-		}
+		answers[i] = toSubmissionAnswerResponse(ans)
 	}
 
 	response := dto.SubmissionResponse{
 		ID:        submissionId,
 		QuizID:    submission.QuizID,
-		UserID:    *submission.UserID,
+		UserID:    ownerId(submission.UserID),
+		Points:    submission.Points,
+		MaxPoints: submission.MaxPoints,
 		Score:     submission.Score,
 		CreatedAt: submission.CreatedAt,
 		UpdatedAt: submission.UpdatedAt,
@@ -82,16 +133,30 @@ func (r *submissionRepository) GetSubmissionById(submissionId uint) (*dto.Submis
 func (r *submissionRepository) CreateSubmission(input *dto.Submission) (*dto.SubmissionResponse, error) {
 	tx := r.db.Begin()
 
+	// members only answer quizzes of their organization, guests were already limited to public quizzes
+	var quiz entity.Quiz
+	quizQuery := tx.Select("id", "unanswered_policy").Where("id = ?", input.QuizID)
+	if input.GuestID == "" {
+		quizQuery = quizQuery.Scopes(inOrg(input.OrgID))
+	}
+	if err := quizQuery.First(&quiz).Error; err != nil {
+		tx.Rollback()
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrQuizNotFound
+		}
+		return nil, err
+	}
+
 	var questions []entity.Question
-	if err := tx.Preload("Answers").Where("quiz_id = ?", input.QuizID).Find(&questions).Error; err != nil {
+	if err := tx.Preload("Answers").Where("quiz_id = ?", input.QuizID).Scopes(byPosition).Find(&questions).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
 	missingQuestions := []uint{}
-	userAnswers := make(map[uint]uint)
+	userAnswers := make(map[uint]dto.SubmissionAnswer)
 	for _, ans := range input.Answers {
-		userAnswers[ans.QuestionID] = ans.AnswerID
+		userAnswers[ans.QuestionID] = ans
 	}
 
 	for _, question := range questions {
@@ -100,42 +165,37 @@ func (r *submissionRepository) CreateSubmission(input *dto.Submission) (*dto.Sub
 		}
 	}
 
-	if len(missingQuestions) > 0 {
+	if len(missingQuestions) > 0 && quiz.UnansweredPolicy == entity.UnansweredRequired {
 		tx.Rollback()
 		return nil, fmt.Errorf("pertanyaan belum dijawab: %v", missingQuestions)
 	}
 
-	var correctCount int
+	var points, maxPoints float64
 	var submissionAnswers []entity.SubmissionUserAnswer
 
 	for _, question := range questions {
-		var correctAnswerID uint
-		for _, ans := range question.Answers {
-			if ans.IsCorrect {
-				correctAnswerID = ans.ID
-				break
-			}
+		reply, answered := userAnswers[question.ID]
+		graded := entity.SubmissionUserAnswer{QuestionID: question.ID, Skipped: true}
+		if answered {
+			graded = gradeAnswer(question, reply)
 		}
+		graded.Points = awardPoints(question, graded, quiz.UnansweredPolicy)
 
-		userAnswerID := userAnswers[question.ID]
-		isCorrect := userAnswerID == correctAnswerID
-		if isCorrect {
-			correctCount++
-		}
-
-		submissionAnswers = append(submissionAnswers, entity.SubmissionUserAnswer{
-			SubmissionID: 0,
-			QuestionID:   question.ID,
-			UserAnswerID: userAnswerID,
-			CorrectID:    correctAnswerID,
-			IsCorrect:    isCorrect,
-		})
+		points += graded.Points
+		maxPoints += question.Points
+		submissionAnswers = append(submissionAnswers, graded)
 	}
 
 	submission := entity.Submission{
-		QuizID: input.QuizID,
-		UserID: &input.UserID,
-		Score:  float32(correctCount) / float32(len(questions)) * 100,
+		QuizID:    input.QuizID,
+		Points:    points,
+		MaxPoints: maxPoints,
+		Score:     scorePercentage(points, maxPoints),
+	}
+	if input.GuestID != "" {
+		submission.GuestID = &input.GuestID
+	} else {
+		submission.UserID = &input.UserID
 	}
 
 	if err := tx.Create(&submission).Error; err != nil {
@@ -156,18 +216,15 @@ func (r *submissionRepository) CreateSubmission(input *dto.Submission) (*dto.Sub
 
 	responseAnswer := make([]dto.SubmissionAnswerResponse, len(submissionAnswers))
 	for i, answer := range submissionAnswers {
-		responseAnswer[i] = dto.SubmissionAnswerResponse{
-			QuestionID:    answer.QuestionID,
-			AnswerUser:    answer.UserAnswerID,
-			CorrectAnswer: answer.CorrectID,
-			IsCorrect:     answer.IsCorrect,
-		}
+		responseAnswer[i] = toSubmissionAnswerResponse(answer)
 	}
 
 	response := dto.SubmissionResponse{
 		ID:        submission.ID,
 		QuizID:    submission.QuizID,
-		UserID:    *submission.UserID,
+		UserID:    ownerId(submission.UserID),
+		Points:    submission.Points,
+		MaxPoints: submission.MaxPoints,
 		Score:     submission.Score,
 		CreatedAt: submission.CreatedAt,
 		UpdatedAt: submission.UpdatedAt,
@@ -177,22 +234,43 @@ func (r *submissionRepository) CreateSubmission(input *dto.Submission) (*dto.Sub
 	return &response, nil
 }
 
-func (r *submissionRepository) GetQuizIdFromSubmisionId(submisionId uint) (uint, error) {
+func (r *submissionRepository) GetQuizIdFromSubmisionId(orgId, submisionId uint) (uint, error) {
 	var quizId uint
-	if err := r.db.Model(&entity.Submission{}).Select("quiz_id").Where("id  = ?", submisionId).First(&quizId).Error; err != nil {
+	if err := r.db.Model(&entity.Submission{}).Select("quiz_id").Where("id  = ? AND quiz_id IN (?)", submisionId, quizzesInOrg(r.db, orgId)).First(&quizId).Error; err != nil {
 		return 0, err
 	}
 
 	return quizId, nil
 }
 
+// UpdateSubmission keeps points and score in step, a score override turns into points of the max points.
 func (r *submissionRepository) UpdateSubmission(input *dto.SubmissionUpdate) (*dto.JustSubmissionResponse, error) {
-	updated := r.db.Model(&entity.Submission{}).Where("id = ?", input.SubmissionID).Updates(map[string]interface{}{"score": input.Score, "updated_at": time.Now()})
-	if updated.Error != nil {
-		return nil, updated.Error
-	}
-	if updated.RowsAffected == 0 {
-		return nil, helper.ErrSubmissionNotFound
+	var submission entity.Submission
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", input.SubmissionID).First(&submission).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return helper.ErrSubmissionNotFound
+			}
+			return err
+		}
+
+		if input.Points != nil {
+			// old submissions have no max points to turn points into a percentage
+			if submission.MaxPoints <= 0 {
+				return helper.ErrNoMaxPoints
+			}
+			submission.Points = *input.Points
+			submission.Score = scorePercentage(submission.Points, submission.MaxPoints)
+		} else {
+			submission.Score = *input.Score
+			submission.Points = float64(submission.Score) / 100 * submission.MaxPoints
+		}
+
+		return tx.Model(&entity.Submission{}).Where("id = ?", submission.ID).
+			Updates(map[string]interface{}{"points": submission.Points, "score": submission.Score, "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	var parsingResponse entity.Submission
@@ -202,7 +280,12 @@ func (r *submissionRepository) UpdateSubmission(input *dto.SubmissionUpdate) (*d
 
 	response := dto.JustSubmissionResponse{
 		ID:        parsingResponse.ID,
+		QuizID:    parsingResponse.QuizID,
+		UserID:    ownerId(parsingResponse.UserID),
+		Points:    parsingResponse.Points,
+		MaxPoints: parsingResponse.MaxPoints,
 		Score:     parsingResponse.Score,
+		CreatedAt: parsingResponse.CreatedAt,
 		UpdatedAt: parsingResponse.UpdatedAt,
 	}
 
@@ -220,3 +303,40 @@ func (r *submissionRepository) DeleteSubmission(submissionId uint) error {
 
 	return nil
 }
+
+func toSubmissionAnswerResponse(ans entity.SubmissionUserAnswer) dto.SubmissionAnswerResponse {
+	isCorrect := ans.IsCorrect
+	points := ans.Points
+	response := dto.SubmissionAnswerResponse{
+		QuestionID: ans.QuestionID,
+		AnswerUser: ans.UserAnswerID,
+		Skipped:    ans.Skipped,
+		IsCorrect:  &isCorrect,
+		Points:     &points,
+	}
+	if ans.CorrectID != 0 {
+		correctId := ans.CorrectID
+		response.CorrectAnswer = &correctId
+	}
+
+	// replies other than a single answer id are kept as json, see gradeAnswer
+	var reply dto.SubmissionAnswer
+	if ans.Response != "" && json.Unmarshal([]byte(ans.Response), &reply) == nil {
+		response.AnswerIDs = reply.AnswerIDs
+		response.Text = reply.Text
+		response.Pairs = reply.Pairs
+	}
+	if ans.CorrectIDs != "" {
+		_ = json.Unmarshal([]byte(ans.CorrectIDs), &response.CorrectAnswers)
+	}
+
+	return response
+}
+
+// ownerId is 0 for old submissions whose user was hard deleted before accounts were soft deleted.
+func ownerId(userId *uint) uint {
+	if userId == nil {
+		return 0
+	}
+	return *userId
+}
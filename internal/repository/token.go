@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TokenRepository interface {
+	//refresh token
+	CreateRefreshToken(token *entity.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*entity.RefreshToken, error)
+	RotateRefreshToken(oldId uint, newToken *entity.RefreshToken) error
+	RevokeRefreshTokenFamily(familyId string) error
+	RevokeAllRefreshTokens(userId uint) error
+
+	//single use token
+	CreateUserToken(token *entity.UserToken) error
+	GetUserToken(hash, purpose string) (*entity.UserToken, error)
+	ConsumeUserToken(hash, purpose string) (*entity.UserToken, error)
+	InvalidateUserTokens(userId uint, purpose string) error
+	CountUserTokensSince(userId uint, purpose string, since time.Time) (int64, error)
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *entity.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *tokenRepository) GetRefreshTokenByHash(hash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RotateRefreshToken revokes the old token and stores its replacement atomically.
+// If the old token was already revoked by a concurrent request it is treated as reuse.
+func (r *tokenRepository) RotateRefreshToken(oldId uint, newToken *entity.RefreshToken) error {
+	tx := r.db.Begin()
+
+	revoked := tx.Model(&entity.RefreshToken{}).Where("id = ? AND revoked_at IS NULL", oldId).Update("revoked_at", time.Now())
+	if revoked.Error != nil {
+		tx.Rollback()
+		return revoked.Error
+	}
+	if revoked.RowsAffected == 0 {
+		tx.Rollback()
+		return helper.ErrRefreshTokenReused
+	}
+
+	if err := tx.Create(newToken).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *tokenRepository) RevokeRefreshTokenFamily(familyId string) error {
+	return r.db.Model(&entity.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", familyId).Update("revoked_at", time.Now()).Error
+}
+
+func (r *tokenRepository) RevokeAllRefreshTokens(userId uint) error {
+	return r.db.Model(&entity.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userId).Update("revoked_at", time.Now()).Error
+}
+
+func (r *tokenRepository) CreateUserToken(token *entity.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetUserToken returns a token that can still be redeemed without using it up.
+func (r *tokenRepository) GetUserToken(hash, purpose string) (*entity.UserToken, error) {
+	var token entity.UserToken
+	if err := r.db.Where("token_hash = ? AND purpose = ?", hash, purpose).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, helper.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, helper.ErrInvalidToken
+	}
+
+	return &token, nil
+}
+
+// ConsumeUserToken marks a valid token as used so it cannot be redeemed twice.
+func (r *tokenRepository) ConsumeUserToken(hash, purpose string) (*entity.UserToken, error) {
+	token, err := r.GetUserToken(hash, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	used := r.db.Model(&entity.UserToken{}).Where("id = ? AND used_at IS NULL", token.ID).Update("used_at", now)
+	if used.Error != nil {
+		return nil, used.Error
+	}
+	if used.RowsAffected == 0 {
+		return nil, helper.ErrInvalidToken
+	}
+
+	token.UsedAt = &now
+	return token, nil
+}
+
+func (r *tokenRepository) InvalidateUserTokens(userId uint, purpose string) error {
+	return r.db.Model(&entity.UserToken{}).Where("user_id = ? AND purpose = ? AND used_at IS NULL", userId, purpose).Update("used_at", time.Now()).Error
+}
+
+func (r *tokenRepository) CountUserTokensSince(userId uint, purpose string, since time.Time) (int64, error) {
+	var total int64
+	if err := r.db.Model(&entity.UserToken{}).Where("user_id = ? AND purpose = ? AND created_at >= ?", userId, purpose, since).Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
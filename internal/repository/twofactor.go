@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	SaveTOTPSecret(userId uint, secret string) error
+	EnableTOTP(userId uint, codeHashes []string) error
+	DisableTOTP(userId uint) error
+	UseTOTPStep(userId uint, step int64) error
+
+	//recovery code
+	ReplaceRecoveryCodes(userId uint, codeHashes []string) error
+	UseRecoveryCode(userId uint, hash string) error
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db}
+}
+
+func (r *twoFactorRepository) SaveTOTPSecret(userId uint, secret string) error {
+	updated := r.db.Model(&entity.User{}).Where("id = ? AND totp_enabled = ?", userId, false).Updates(map[string]any{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	})
+	if updated.Error != nil {
+		return updated.Error
+	}
+	if updated.RowsAffected == 0 {
+		return helper.ErrTOTPAlreadyEnabled
+	}
+
+	return nil
+}
+
+// EnableTOTP turns 2fa on and stores the first set of recovery codes in one transaction.
+func (r *twoFactorRepository) EnableTOTP(userId uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updated := tx.Model(&entity.User{}).Where("id = ? AND totp_enabled = ?", userId, false).Update("totp_enabled", true)
+		if updated.Error != nil {
+			return updated.Error
+		}
+		if updated.RowsAffected == 0 {
+			return helper.ErrTOTPAlreadyEnabled
+		}
+
+		return replaceRecoveryCodes(tx, userId, codeHashes)
+	})
+}
+
+func (r *twoFactorRepository) DisableTOTP(userId uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.User{}).Where("id = ?", userId).Updates(map[string]any{
+			"totp_secret":    "",
+			"totp_enabled":   false,
+			"totp_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("user_id = ?", userId).Delete(&entity.RecoveryCode{}).Error
+	})
+}
+
+// UseTOTPStep only moves forward, so a code that was already accepted cannot be replayed.
+func (r *twoFactorRepository) UseTOTPStep(userId uint, step int64) error {
+	updated := r.db.Model(&entity.User{}).Where("id = ? AND totp_last_step < ?", userId, step).Update("totp_last_step", step)
+	if updated.Error != nil {
+		return updated.Error
+	}
+	if updated.RowsAffected == 0 {
+		return helper.ErrInvalidTOTPCode
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userId uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userId, codeHashes)
+	})
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(userId uint, hash string) error {
+	used := r.db.Model(&entity.RecoveryCode{}).Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, hash).Update("used_at", time.Now())
+	if used.Error != nil {
+		return used.Error
+	}
+	if used.RowsAffected == 0 {
+		return helper.ErrInvalidTOTPCode
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userId uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userId).Delete(&entity.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]entity.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, entity.RecoveryCode{UserID: userId, CodeHash: hash})
+	}
+
+	return tx.Create(&codes).Error
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"slices"
+	"strings"
+	"time"
+)
+
+type APIKeyUseCase interface {
+	CreateAPIKey(userId, orgId uint, input *dto.CreateAPIKey) (*dto.CreatedAPIKeyResponse, error)
+	GetAPIKeys(userId uint) ([]dto.APIKeyResponse, error)
+	RevokeAPIKey(userId, id uint) error
+}
+
+const maxAPIKeysPerUser = 10
+
+var validScopes = []string{entity.ScopeQuizRead, entity.ScopeQuizWrite, entity.ScopeSubmissionRead, entity.ScopeSubmissionWrite}
+
+type apiKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+func NewAPIKeyUseCase(apiKeyRepo repository.APIKeyRepository) APIKeyUseCase {
+	return &apiKeyUseCase{apiKeyRepo}
+}
+
+// CreateAPIKey binds the key to the organization the user is working in, 0 for the shared space.
+func (u *apiKeyUseCase) CreateAPIKey(userId, orgId uint, input *dto.CreateAPIKey) (*dto.CreatedAPIKeyResponse, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" || len(input.Name) > 100 {
+		return nil, helper.ErrInvalidKeyName
+	}
+	if input.ExpiresInDays < 0 {
+		return nil, helper.ErrInvalidKeyExpiry
+	}
+
+	var scopes []string
+	for _, scope := range input.Scopes {
+		if !slices.Contains(validScopes, scope) {
+			return nil, helper.ErrInvalidScope
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	total, err := u.apiKeyRepo.CountActiveAPIKeys(userId)
+	if err != nil {
+		return nil, err
+	}
+	if total >= maxAPIKeysPerUser {
+		return nil, helper.ErrTooManyAPIKeys
+	}
+
+	key, prefix, err := helper.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	record := entity.APIKey{
+		UserID:  userId,
+		Name:    input.Name,
+		Prefix:  prefix,
+		KeyHash: helper.HashToken(key),
+		Scopes:  strings.Join(scopes, " "),
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		record.ExpiresAt = &expiresAt
+	}
+	if orgId != 0 {
+		record.OrganizationID = &orgId
+	}
+	if err := u.apiKeyRepo.CreateAPIKey(&record); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreatedAPIKeyResponse{
+		APIKeyResponse: apiKeyResponse(record),
+		Key:            key,
+	}, nil
+}
+
+func (u *apiKeyUseCase) GetAPIKeys(userId uint) ([]dto.APIKeyResponse, error) {
+	keys, err := u.apiKeyRepo.GetAPIKeysByUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, apiKeyResponse(key))
+	}
+
+	return response, nil
+}
+
+func (u *apiKeyUseCase) RevokeAPIKey(userId, id uint) error {
+	return u.apiKeyRepo.RevokeAPIKey(userId, id)
+}
+
+func apiKeyResponse(key entity.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:             key.ID,
+		Name:           key.Name,
+		Prefix:         key.Prefix,
+		Scopes:         strings.Fields(key.Scopes),
+		ExpiresAt:      key.ExpiresAt,
+		LastUsedAt:     key.LastUsedAt,
+		RevokedAt:      key.RevokedAt,
+		CreatedAt:      key.CreatedAt,
+		OrganizationID: key.OrganizationID,
+	}
+}
@@ -2,53 +2,142 @@ package usecase
 
 import (
 	"api_quiz/dto"
+	"api_quiz/entity"
 	"api_quiz/internal/repository"
 	"api_quiz/utils/helper"
+	"api_quiz/utils/mailer"
+	"log"
+	"strings"
+	"time"
 )
 
 type AuthUseCase interface {
-	Login(dto *dto.Login) (string, error)
+	Login(dto *dto.Login) (*dto.LoginResponse, error)
 	Register(input *dto.Register) error
 	DeleteUser(id uint) error
+	RestoreAccount(token string) error
+	PurgeDeletedUsers() (int, error)
 
 	ValidateUser(id uint) error
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+
+	//token
+	RefreshToken(input *dto.RefreshToken) (*dto.TokenResponse, error)
+	Logout(claims *helper.JWTClaims, refreshToken string) error
+	RevokeAllSessions(userId uint) error
+
+	//password
+	ForgotPassword(email string) error
+	ResetPassword(input *dto.ResetPassword) error
+	UnlockAccount(token string) error
+
+	//admin
+	UpdateRole(actor dto.Actor, userId uint, role string) error
 }
 
+const (
+	passwordResetTTL          = 30 * time.Minute
+	verificationTTL           = 24 * time.Hour
+	verificationResendPerHour = 5
+
+	// brute force protection, every failure counts for the email and for the ip
+	loginFailureWindow   = 15 * time.Minute
+	loginDelayAfter      = 3
+	loginMaxDelay        = time.Minute
+	loginLockAfter       = 10
+	loginMaxFailuresByIP = 50
+	unlockTTL            = time.Hour
+
+	// deleted accounts can be restored for this long, after that the purge anonymizes them
+	deletedAccountRetention = 30 * 24 * time.Hour
+	purgeBatchSize          = 100
+)
+
+// compared against when the email is unknown so both cases take as long as a real argon2id check
+const dummyPasswordHash = "$argon2id$v=19$m=19456,t=2,p=1$mVEZMboaEtSLTh2igSxN7A$jB/BO1ZWw015C4Mp+K+53YZco4wDoLCf8mrd5fmzvkY"
+
 type authUseCase struct {
-	authRepo repository.AuthRepository
+	authRepo       repository.AuthRepository
+	tokenRepo      repository.TokenRepository
+	sessionRepo    repository.SessionRepository
+	revocationRepo repository.RevocationRepository
+	attemptRepo    repository.LoginAttemptRepository
+	submissionRepo repository.SubmissionRepository
+	passwordPolicy *helper.PasswordPolicy
+	mailer         mailer.Mailer
+	baseURL        string
+	frontendURL    string
+	lockout        *loginLockout
 }
 
-func NewAuthUseCase(authRepo repository.AuthRepository) AuthUseCase {
-	return &authUseCase{authRepo}
+func NewAuthUseCase(authRepo repository.AuthRepository, tokenRepo repository.TokenRepository, sessionRepo repository.SessionRepository, revocationRepo repository.RevocationRepository, attemptRepo repository.LoginAttemptRepository, submissionRepo repository.SubmissionRepository, passwordPolicy *helper.PasswordPolicy, mailer mailer.Mailer, baseURL, frontendURL string) AuthUseCase {
+	lockout := newLoginLockout(attemptRepo, tokenRepo, mailer, baseURL)
+	return &authUseCase{authRepo, tokenRepo, sessionRepo, revocationRepo, attemptRepo, submissionRepo, passwordPolicy, mailer, baseURL, frontendURL, lockout}
 }
 
-func (u *authUseCase) Login(dto *dto.Login) (string, error) {
+// Login answers unknown emails and wrong passwords with the same error, and throttles both
+// per email and per ip so neither can be brute forced.
+func (u *authUseCase) Login(input *dto.Login) (*dto.LoginResponse, error) {
 
-	if !helper.IsValidEmail(dto.Email) {
-		return "", helper.ErrInvalidEmail
+	if !helper.IsValidEmail(input.Email) {
+		return nil, helper.ErrInvalidEmail
 	}
+	email := strings.ToLower(strings.TrimSpace(input.Email))
 
-	user, err := u.authRepo.Login(dto)
-	if err != nil {
-		return "", err
+	if err := u.lockout.check(email, input.Client.IP); err != nil {
+		// still hash so a locked account takes as long as a wrong password
+		helper.ComparePassword(dummyPasswordHash, input.Password)
+		return nil, err
+	}
+
+	user, err := u.authRepo.Login(input)
+	if err != nil && err != helper.ErrUserNotFound {
+		return nil, err
+	}
+
+	if user == nil {
+		helper.ComparePassword(dummyPasswordHash, input.Password)
+		return nil, u.recordFailedLogin(email, input.Client.IP, nil)
+	}
+	if !helper.ComparePassword(user.Password, input.Password) {
+		return nil, u.recordFailedLogin(email, input.Client.IP, user)
 	}
 
-	if !helper.ComparePassword(user.Password, dto.Password) {
-		return "", err
+	// bcrypt and outdated argon2id hashes are upgraded while the plain password is at hand
+	if helper.NeedsRehash(user.Password) {
+		if err := u.rehashPassword(user, input.Password); err != nil {
+			log.Printf("failed rehash password user %d %v", user.ID, err)
+		}
 	}
 
-	token, err := helper.GenerateJWTLogin(user.ID, user.Email, user.IsVerified)
+	response, err := loginResponse(u.tokenRepo, u.sessionRepo, user, input.Client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return token, nil
+	// with 2fa on the failures are only cleared and the guest token claimed by /login/2fa,
+	// otherwise a known password would reset the count of wrong codes
+	if response.TokenResponse != nil {
+		if err := u.lockout.clear(email); err != nil {
+			return nil, err
+		}
+		claimGuestOnLogin(u.submissionRepo, u.revocationRepo, user.ID, input.GuestToken)
+	}
+
+	return response, nil
 }
 
 func (u *authUseCase) Register(input *dto.Register) error {
 	if !helper.IsValidEmail(input.Email) {
 		return helper.ErrInvalidEmail
 	}
+
+	// creators and admins can only be promoted by an admin
+	input.Role = entity.RoleStudent
+	if err := u.passwordPolicy.Check(input.Password, input.Email, input.Username); err != nil {
+		return err
+	}
 	hashed, err := helper.HashPassword(input.Password)
 	if err != nil {
 		return err
@@ -60,19 +149,467 @@ func (u *authUseCase) Register(input *dto.Register) error {
 		return err
 	}
 
-	token, err := helper.GenerateJWTRegister(user.ID, user.Email)
+	claimGuestOnLogin(u.submissionRepo, u.revocationRepo, user.ID, input.GuestToken)
+	return u.sendVerification(user)
+}
+
+// DeleteUser only soft deletes, the owner gets a link to restore the account during the grace period.
+func (u *authUseCase) DeleteUser(id uint) error {
+	user, err := u.authRepo.GetUserById(id)
 	if err != nil {
 		return err
 	}
 
-	helper.SendEmail(user.Email, token)
-	return nil
+	if err := u.authRepo.DeleteUser(id); err != nil {
+		return err
+	}
+	if err := u.RevokeAllSessions(id); err != nil {
+		return err
+	}
+
+	token, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	record := entity.UserToken{
+		UserID:    user.ID,
+		Purpose:   entity.TokenPurposeRestore,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(deletedAccountRetention),
+	}
+	if err := u.tokenRepo.CreateUserToken(&record); err != nil {
+		return err
+	}
+
+	return sendMail(u.mailer, user.Email, "Your Account Was Deleted", mailer.TemplateNotification, map[string]any{
+		"Username": user.Username,
+		"Message":  "Akun kamu sudah dihapus. Selama 30 hari ke depan akun masih bisa dikembalikan lewat link di bawah, setelah itu data pribadi kamu dihapus permanen.",
+		"Link":     buildLink(u.baseURL, "/account/restore", map[string]string{"token": token}),
+	})
 }
 
-func (u *authUseCase) DeleteUser(id uint) error {
-	return u.authRepo.DeleteUser(id)
+func (u *authUseCase) RestoreAccount(token string) error {
+	record, err := u.tokenRepo.ConsumeUserToken(helper.HashToken(token), entity.TokenPurposeRestore)
+	if err != nil {
+		return err
+	}
+
+	return u.authRepo.RestoreUser(record.UserID, time.Now().Add(-deletedAccountRetention))
+}
+
+// PurgeDeletedUsers anonymizes every account whose grace period is over and returns how many were purged.
+func (u *authUseCase) PurgeDeletedUsers() (int, error) {
+	before := time.Now().Add(-deletedAccountRetention)
+
+	purged := 0
+	for {
+		users, err := u.authRepo.GetUsersToPurge(before, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for i := range users {
+			if err := u.authRepo.AnonymizeUser(&users[i]); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(users) < purgeBatchSize {
+			return purged, nil
+		}
+	}
 }
 
 func (u *authUseCase) ValidateUser(id uint) error {
+	user, err := u.authRepo.GetUserById(id)
+	if err != nil {
+		return err
+	}
+	if user.IsVerified {
+		return helper.ErrAlreadyVerified
+	}
+
 	return u.authRepo.ValidateUser(id)
 }
+
+func (u *authUseCase) VerifyEmail(token string) error {
+	record, err := u.tokenRepo.ConsumeUserToken(helper.HashToken(token), entity.TokenPurposeVerification)
+	if err != nil {
+		return err
+	}
+
+	return u.ValidateUser(record.UserID)
+}
+
+// ResendVerification answers the same way for unknown and verified emails, only throttling is reported.
+func (u *authUseCase) ResendVerification(email string) error {
+	if !helper.IsValidEmail(email) {
+		return helper.ErrInvalidEmail
+	}
+
+	user, err := u.authRepo.GetUserByEmail(email)
+	if err != nil {
+		if err == helper.ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+	if user.IsVerified {
+		return nil
+	}
+
+	lastMinute, err := u.tokenRepo.CountUserTokensSince(user.ID, entity.TokenPurposeVerification, time.Now().Add(-time.Minute))
+	if err != nil {
+		return err
+	}
+	lastHour, err := u.tokenRepo.CountUserTokensSince(user.ID, entity.TokenPurposeVerification, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if lastMinute > 0 || lastHour >= verificationResendPerHour {
+		return helper.ErrTooManyRequests
+	}
+
+	if err := u.tokenRepo.InvalidateUserTokens(user.ID, entity.TokenPurposeVerification); err != nil {
+		return err
+	}
+
+	return u.sendVerification(user)
+}
+
+func (u *authUseCase) RefreshToken(input *dto.RefreshToken) (*dto.TokenResponse, error) {
+	current, err := u.tokenRepo.GetRefreshTokenByHash(helper.HashToken(input.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	// a revoked token coming back means it leaked, so the whole family is killed
+	if current.RevokedAt != nil {
+		if err := u.tokenRepo.RevokeRefreshTokenFamily(current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, helper.ErrRefreshTokenReused
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return nil, helper.ErrInvalidRefreshToken
+	}
+
+	user, err := u.authRepo.GetUserById(current.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	newToken, record, err := newRefreshToken(user.ID, current.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.tokenRepo.RotateRefreshToken(current.ID, record); err != nil {
+		if err == helper.ErrRefreshTokenReused {
+			if err := u.tokenRepo.RevokeRefreshTokenFamily(current.FamilyID); err != nil {
+				return nil, err
+			}
+			return nil, helper.ErrRefreshTokenReused
+		}
+		return nil, err
+	}
+
+	session, err := u.sessionRepo.GetSessionByFamily(current.FamilyID)
+	if err != nil {
+		if err != helper.ErrSessionNotFound {
+			return nil, err
+		}
+		// families from before sessions existed get their session on the first refresh
+		session, err = newSession(u.sessionRepo, user, current.FamilyID, input.Client)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := u.sessionRepo.TouchSession(session.ID, input.Client.IP, input.Client.UserAgent, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return tokenResponse(user, newToken, session)
+}
+
+func (u *authUseCase) Logout(claims *helper.JWTClaims, refreshToken string) error {
+	if refreshToken != "" {
+		current, err := u.tokenRepo.GetRefreshTokenByHash(helper.HashToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		if current.UserID != claims.UserID {
+			return helper.ErrInvalidRefreshToken
+		}
+		if err := u.tokenRepo.RevokeRefreshTokenFamily(current.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID != 0 {
+		if err := revokeSession(u.tokenRepo, u.sessionRepo, claims.UserID, claims.SessionID); err != nil && err != helper.ErrSessionNotFound {
+			return err
+		}
+	}
+
+	return u.revocationRepo.RevokeToken(claims.ID, claims.ExpiresAt.Time)
+}
+
+func (u *authUseCase) RevokeAllSessions(userId uint) error {
+	return revokeSessions(u.tokenRepo, u.revocationRepo, userId)
+}
+
+// ForgotPassword never reports unknown emails so it cannot be used to probe accounts.
+func (u *authUseCase) ForgotPassword(email string) error {
+	if !helper.IsValidEmail(email) {
+		return helper.ErrInvalidEmail
+	}
+
+	user, err := u.authRepo.GetUserByEmail(email)
+	if err != nil {
+		if err == helper.ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+	if !user.IsVerified {
+		return nil
+	}
+
+	if err := u.tokenRepo.InvalidateUserTokens(user.ID, entity.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	token, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	record := entity.UserToken{
+		UserID:    user.ID,
+		Purpose:   entity.TokenPurposePasswordReset,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	if err := u.tokenRepo.CreateUserToken(&record); err != nil {
+		return err
+	}
+
+	return sendMail(u.mailer, user.Email, "Reset Your Password", mailer.TemplateResetPassword, map[string]any{
+		"Username":  user.Username,
+		"Token":     token,
+		"Link":      passwordResetLink(u.frontendURL, token),
+		"ExpiresIn": "30 menit",
+	})
+}
+
+// ResetPassword checks the new password before using up the token, so a rejected password
+// does not cost the user their reset link.
+func (u *authUseCase) ResetPassword(input *dto.ResetPassword) error {
+	tokenHash := helper.HashToken(input.Token)
+	pending, err := u.tokenRepo.GetUserToken(tokenHash, entity.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.authRepo.GetUserById(pending.UserID)
+	if err != nil {
+		return err
+	}
+	if err := u.passwordPolicy.Check(input.Password, user.Email, user.Username); err != nil {
+		return err
+	}
+
+	record, err := u.tokenRepo.ConsumeUserToken(tokenHash, entity.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := helper.HashPassword(input.Password)
+	if err != nil {
+		return err
+	}
+	if err := u.authRepo.UpdatePassword(record.UserID, hashed); err != nil {
+		return err
+	}
+
+	if err := u.RevokeAllSessions(record.UserID); err != nil {
+		return err
+	}
+
+	return sendMail(u.mailer, user.Email, "Your Password Was Changed", mailer.TemplateNotification, map[string]any{
+		"Username": user.Username,
+		"Message":  "Password akun kamu baru saja direset dan semua sesi login sudah dikeluarkan. Kalau ini bukan kamu, segera reset password lagi.",
+	})
+}
+
+func (u *authUseCase) UnlockAccount(token string) error {
+	record, err := u.tokenRepo.ConsumeUserToken(helper.HashToken(token), entity.TokenPurposeUnlock)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.authRepo.GetUserById(record.UserID)
+	if err != nil {
+		return err
+	}
+
+	return u.attemptRepo.ClearFailedByEmail(strings.ToLower(user.Email))
+}
+
+func (u *authUseCase) UpdateRole(actor dto.Actor, userId uint, role string) error {
+	if !isAdmin(actor) {
+		return helper.ErrForbidden
+	}
+	if !isValidRole(role) {
+		return helper.ErrInvalidRole
+	}
+
+	if err := u.authRepo.UpdateRole(userId, role); err != nil {
+		return err
+	}
+
+	// tokens carry the role, so old ones must not keep the previous permissions
+	return u.RevokeAllSessions(userId)
+}
+
+func (u *authUseCase) rehashPassword(user *entity.User, password string) error {
+	hashed, err := helper.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return u.authRepo.UpdatePassword(user.ID, hashed)
+}
+
+// issueTokens starts a new session with its own refresh token family, shared by every login method.
+func issueTokens(tokenRepo repository.TokenRepository, sessionRepo repository.SessionRepository, user *entity.User, client dto.ClientInfo) (*dto.TokenResponse, error) {
+	familyId, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := newSession(sessionRepo, user, familyId, client)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, record, err := newRefreshToken(user.ID, familyId)
+	if err != nil {
+		return nil, err
+	}
+	if err := tokenRepo.CreateRefreshToken(record); err != nil {
+		return nil, err
+	}
+
+	return tokenResponse(user, refreshToken, session)
+}
+
+// newSession starts in the organization the user was last active in.
+func newSession(sessionRepo repository.SessionRepository, user *entity.User, familyId string, client dto.ClientInfo) (*entity.Session, error) {
+	userAgent := client.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	session := entity.Session{
+		UserID:         user.ID,
+		FamilyID:       familyId,
+		OrganizationID: user.ActiveOrganizationID,
+		UserAgent:      userAgent,
+		IP:             client.IP,
+		LastSeenAt:     time.Now(),
+	}
+	if err := sessionRepo.CreateSession(&session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// revokeSession ends one device, its refresh tokens die with the family and the middleware
+// rejects access tokens that still carry the session id.
+func revokeSession(tokenRepo repository.TokenRepository, sessionRepo repository.SessionRepository, userId, sessionId uint) error {
+	session, err := sessionRepo.RevokeSession(userId, sessionId)
+	if err != nil {
+		return err
+	}
+
+	return tokenRepo.RevokeRefreshTokenFamily(session.FamilyID)
+}
+
+// revokeSessions kills every refresh token and every access token issued so far for the user.
+func revokeSessions(tokenRepo repository.TokenRepository, revocationRepo repository.RevocationRepository, userId uint) error {
+	if err := tokenRepo.RevokeAllRefreshTokens(userId); err != nil {
+		return err
+	}
+
+	return revocationRepo.RevokeUser(userId)
+}
+
+func tokenResponse(user *entity.User, refreshToken string, session *entity.Session) (*dto.TokenResponse, error) {
+	var orgId uint
+	if session.OrganizationID != nil {
+		orgId = *session.OrganizationID
+	}
+
+	accessToken, err := helper.GenerateJWTLogin(user.ID, user.Email, user.Role, user.IsVerified, session.ID, orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(helper.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func newRefreshToken(userId uint, familyId string) (string, *entity.RefreshToken, error) {
+	token, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	record := &entity.RefreshToken{
+		UserID:    userId,
+		FamilyID:  familyId,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(helper.RefreshTokenTTL),
+	}
+
+	return token, record, nil
+}
+
+// recordFailedLogin always ends in ErrInvalidLogin so an unknown email and a wrong password look the same.
+func (u *authUseCase) recordFailedLogin(email, ip string, user *entity.User) error {
+	if err := u.lockout.recordFailure(email, ip, user); err != nil {
+		return err
+	}
+	return helper.ErrInvalidLogin
+}
+
+func (u *authUseCase) sendVerification(user *entity.User) error {
+	token, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	record := entity.UserToken{
+		UserID:    user.ID,
+		Purpose:   entity.TokenPurposeVerification,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(verificationTTL),
+	}
+	if err := u.tokenRepo.CreateUserToken(&record); err != nil {
+		return err
+	}
+
+	return sendMail(u.mailer, user.Email, "Verify Your Account", mailer.TemplateVerification, map[string]any{
+		"Username":  user.Username,
+		"Link":      buildLink(u.baseURL, "/verification", map[string]string{"token": token}),
+		"ExpiresIn": "24 jam",
+	})
+}
@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"strings"
+)
+
+type ClassUseCase interface {
+	CreateClass(actor dto.Actor, input *dto.CreateClass) (*dto.ClassResponse, error)
+	GetMyClasses(actor dto.Actor) ([]dto.ClassResponse, error)
+	GetClass(actor dto.Actor, classId uint) (*dto.ClassResponse, error)
+	RegenerateJoinCode(actor dto.Actor, classId uint) (*dto.ClassResponse, error)
+	JoinClass(actor dto.Actor, code string) (*dto.ClassResponse, error)
+
+	//roster
+	GetMembers(actor dto.Actor, classId uint) ([]dto.ClassMemberResponse, error)
+	AddMember(actor dto.Actor, classId uint, input *dto.AddClassMember) error
+	RemoveMember(actor dto.Actor, classId, userId uint) error
+
+	//class content
+	GetClassQuizzes(actor dto.Actor, classId uint) ([]dto.JustQuizResponse, error)
+	GetClassSubmissions(actor dto.Actor, classId uint) ([]dto.JustSubmissionResponse, error)
+}
+
+const (
+	classRoleTeacher = "teacher"
+	classRoleStudent = "student"
+)
+
+type classUseCase struct {
+	classRepo      repository.ClassRepository
+	quizRepo       repository.QuizRepository
+	submissionRepo repository.SubmissionRepository
+	authRepo       repository.AuthRepository
+	orgRepo        repository.OrganizationRepository
+	baseURL        string
+}
+
+func NewClassUseCase(classRepo repository.ClassRepository, quizRepo repository.QuizRepository, submissionRepo repository.SubmissionRepository, authRepo repository.AuthRepository, orgRepo repository.OrganizationRepository, baseURL string) ClassUseCase {
+	return &classUseCase{classRepo, quizRepo, submissionRepo, authRepo, orgRepo, baseURL}
+}
+
+// CreateClass is for quiz creators, the class lives in the organization they are working in.
+func (u *classUseCase) CreateClass(actor dto.Actor, input *dto.CreateClass) (*dto.ClassResponse, error) {
+	if !canCreateQuiz(actor) {
+		return nil, helper.ErrForbidden
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" || len(input.Name) > 100 {
+		return nil, helper.ErrInvalidClassName
+	}
+
+	code, err := helper.GenerateJoinCode()
+	if err != nil {
+		return nil, err
+	}
+
+	class := entity.Class{TeacherID: actor.UserID, Name: input.Name, JoinCode: code}
+	if actor.OrgID != 0 {
+		class.OrganizationID = &actor.OrgID
+	}
+	if err := u.classRepo.CreateClass(&class); err != nil {
+		return nil, err
+	}
+
+	return u.classResponse(actor, &class), nil
+}
+
+func (u *classUseCase) GetMyClasses(actor dto.Actor) ([]dto.ClassResponse, error) {
+	classes, err := u.classRepo.GetUserClasses(actor.OrgID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.ClassResponse, len(classes))
+	for i := range classes {
+		response[i] = *u.classResponse(actor, &classes[i])
+	}
+
+	return response, nil
+}
+
+func (u *classUseCase) GetClass(actor dto.Actor, classId uint) (*dto.ClassResponse, error) {
+	class, err := u.viewableClass(actor, classId)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.classResponse(actor, class), nil
+}
+
+// RegenerateJoinCode stops the old code from working, students already in the class stay.
+func (u *classUseCase) RegenerateJoinCode(actor dto.Actor, classId uint) (*dto.ClassResponse, error) {
+	class, err := u.managedClass(actor, classId)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := helper.GenerateJoinCode()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.classRepo.UpdateJoinCode(class.ID, code); err != nil {
+		return nil, err
+	}
+
+	class.JoinCode = code
+	return u.classResponse(actor, class), nil
+}
+
+// JoinClass only finds classes of the organization the student is working in.
+func (u *classUseCase) JoinClass(actor dto.Actor, code string) (*dto.ClassResponse, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, helper.ErrInvalidJoinCode
+	}
+
+	class, err := u.classRepo.GetClassByJoinCode(actor.OrgID, code)
+	if err != nil {
+		if err == helper.ErrClassNotFound {
+			return nil, helper.ErrInvalidJoinCode
+		}
+		return nil, err
+	}
+	if class.TeacherID == actor.UserID {
+		return nil, helper.ErrAlreadyInClass
+	}
+
+	if err := u.classRepo.AddClassMember(class.ID, actor.UserID); err != nil {
+		return nil, err
+	}
+
+	return u.classResponse(actor, class), nil
+}
+
+func (u *classUseCase) GetMembers(actor dto.Actor, classId uint) ([]dto.ClassMemberResponse, error) {
+	class, err := u.managedClass(actor, classId)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.classRepo.GetClassMembers(class.ID)
+}
+
+// AddMember puts an existing account in the class, inside an organization it has to be a member of it.
+// Outside an organization only admins add accounts, everyone else joins with the join code.
+func (u *classUseCase) AddMember(actor dto.Actor, classId uint, input *dto.AddClassMember) error {
+	class, err := u.managedClass(actor, classId)
+	if err != nil {
+		return err
+	}
+	if class.OrganizationID == nil && !isAdmin(actor) {
+		return helper.ErrJoinCodeRequired
+	}
+
+	user, err := u.authRepo.GetUserByEmail(strings.TrimSpace(input.Email))
+	if err != nil {
+		return err
+	}
+	if class.OrganizationID != nil {
+		// same answer as an unknown email so the endpoint can not tell accounts outside the organization apart
+		if _, err := u.orgRepo.GetMembership(*class.OrganizationID, user.ID); err != nil {
+			if err == helper.ErrNotOrgMember {
+				return helper.ErrUserNotFound
+			}
+			return err
+		}
+	}
+	if class.TeacherID == user.ID {
+		return helper.ErrAlreadyInClass
+	}
+
+	return u.classRepo.AddClassMember(class.ID, user.ID)
+}
+
+// RemoveMember is for the teacher, students can also remove themselves to leave the class.
+func (u *classUseCase) RemoveMember(actor dto.Actor, classId, userId uint) error {
+	class, err := u.classRepo.GetClassById(actor.OrgID, classId)
+	if err != nil {
+		return err
+	}
+	if userId != actor.UserID && !canManageClass(actor, class) {
+		return helper.ErrUnauhorized
+	}
+
+	return u.classRepo.RemoveClassMember(class.ID, userId)
+}
+
+func (u *classUseCase) GetClassQuizzes(actor dto.Actor, classId uint) ([]dto.JustQuizResponse, error) {
+	class, err := u.viewableClass(actor, classId)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.quizRepo.GetQuizzesByClass(class.ID)
+}
+
+func (u *classUseCase) GetClassSubmissions(actor dto.Actor, classId uint) ([]dto.JustSubmissionResponse, error) {
+	class, err := u.managedClass(actor, classId)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.submissionRepo.GetSubmissionsByClass(class.ID)
+}
+
+func (u *classUseCase) managedClass(actor dto.Actor, classId uint) (*entity.Class, error) {
+	class, err := u.classRepo.GetClassById(actor.OrgID, classId)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageClass(actor, class) {
+		return nil, helper.ErrUnauhorized
+	}
+
+	return class, nil
+}
+
+// viewableClass hides classes from users outside them, as if they did not exist.
+func (u *classUseCase) viewableClass(actor dto.Actor, classId uint) (*entity.Class, error) {
+	class, err := u.classRepo.GetClassById(actor.OrgID, classId)
+	if err != nil {
+		return nil, err
+	}
+	if canManageClass(actor, class) {
+		return class, nil
+	}
+
+	member, err := u.classRepo.IsClassMember(class.ID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !member {
+		return nil, helper.ErrClassNotFound
+	}
+
+	return class, nil
+}
+
+// classResponse only shows the join code and link to whoever can manage the class.
+func (u *classUseCase) classResponse(actor dto.Actor, class *entity.Class) *dto.ClassResponse {
+	response := &dto.ClassResponse{
+		ID:        class.ID,
+		Name:      class.Name,
+		TeacherID: class.TeacherID,
+		Role:      classRoleStudent,
+		CreatedAt: class.CreatedAt,
+	}
+	if class.TeacherID == actor.UserID {
+		response.Role = classRoleTeacher
+	}
+	if canManageClass(actor, class) {
+		// moderators of the organization are neither teacher nor student of the class
+		if response.Role != classRoleTeacher {
+			response.Role = ""
+		}
+		response.JoinCode = class.JoinCode
+		response.JoinURL = buildLink(u.baseURL, "/class/join", map[string]string{"code": class.JoinCode})
+	}
+
+	return response
+}
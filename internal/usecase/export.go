@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/mailer"
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ExportUseCase interface {
+	RequestExport(userId uint) (*dto.ExportResponse, error)
+	GetExport(userId, id uint) (*dto.ExportResponse, error)
+	Download(token string) (string, error)
+	CleanupExports() error
+}
+
+const (
+	exportLinkTTL    = 24 * time.Hour
+	exportsPerDay    = 3
+	staleExportAfter = time.Hour
+)
+
+type exportUseCase struct {
+	exportRepo repository.ExportRepository
+	authRepo   repository.AuthRepository
+	mailer     mailer.Mailer
+	baseURL    string
+	dir        string
+}
+
+func NewExportUseCase(exportRepo repository.ExportRepository, authRepo repository.AuthRepository, mailer mailer.Mailer, baseURL, dir string) ExportUseCase {
+	return &exportUseCase{exportRepo, authRepo, mailer, baseURL, dir}
+}
+
+// RequestExport returns right away, the archive is built in the background and the
+// download link only works once the export is ready.
+func (u *exportUseCase) RequestExport(userId uint) (*dto.ExportResponse, error) {
+	pending, err := u.exportRepo.HasPendingExport(userId)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, helper.ErrExportPending
+	}
+
+	total, err := u.exportRepo.CountExportsSince(userId, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if total >= exportsPerDay {
+		return nil, helper.ErrTooManyRequests
+	}
+
+	token, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	record := entity.DataExport{
+		UserID:    userId,
+		Status:    entity.ExportPending,
+		TokenHash: helper.HashToken(token),
+	}
+	if err := u.exportRepo.CreateExport(&record); err != nil {
+		return nil, err
+	}
+
+	go u.build(record, token)
+
+	response := exportResponse(&record)
+	response.DownloadURL = u.downloadLink(token)
+	return response, nil
+}
+
+func (u *exportUseCase) GetExport(userId, id uint) (*dto.ExportResponse, error) {
+	record, err := u.exportRepo.GetExportById(userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return exportResponse(record), nil
+}
+
+func (u *exportUseCase) Download(token string) (string, error) {
+	record, err := u.exportRepo.GetExportByTokenHash(helper.HashToken(token))
+	if err != nil {
+		return "", err
+	}
+
+	switch record.Status {
+	case entity.ExportPending:
+		return "", helper.ErrExportNotReady
+	case entity.ExportReady:
+		if record.ExpiresAt == nil || time.Now().After(*record.ExpiresAt) {
+			return "", helper.ErrExportExpired
+		}
+		return record.FilePath, nil
+	case entity.ExportExpired:
+		return "", helper.ErrExportExpired
+	default:
+		return "", helper.ErrExportNotFound
+	}
+}
+
+// CleanupExports deletes archives whose link expired and fails exports that were cut off by a restart.
+func (u *exportUseCase) CleanupExports() error {
+	if err := u.exportRepo.FailStaleExports(time.Now().Add(-staleExportAfter)); err != nil {
+		return err
+	}
+
+	expired, err := u.exportRepo.GetExpiredExports(time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range expired {
+		if err := os.Remove(expired[i].FilePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		expired[i].Status = entity.ExportExpired
+		expired[i].FilePath = ""
+		if err := u.exportRepo.SaveExport(&expired[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *exportUseCase) build(record entity.DataExport, token string) {
+	path, err := u.writeArchive(record)
+	if err != nil {
+		log.Printf("failed build export %d %v", record.ID, err)
+		if path != "" {
+			os.Remove(path)
+		}
+		record.Status = entity.ExportFailed
+		if err := u.exportRepo.SaveExport(&record); err != nil {
+			log.Printf("failed save export %d %v", record.ID, err)
+		}
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(exportLinkTTL)
+	record.Status = entity.ExportReady
+	record.FilePath = path
+	record.CompletedAt = &now
+	record.ExpiresAt = &expiresAt
+	if err := u.exportRepo.SaveExport(&record); err != nil {
+		log.Printf("failed save export %d %v", record.ID, err)
+		return
+	}
+
+	user, err := u.authRepo.GetUserById(record.UserID)
+	if err != nil {
+		log.Printf("failed load user for export %d %v", record.ID, err)
+		return
+	}
+	if err := sendMail(u.mailer, user.Email, "Your Data Export Is Ready", mailer.TemplateNotification, map[string]any{
+		"Username": user.Username,
+		"Message":  "Salinan data akun kamu sudah siap. Link di bawah berlaku 24 jam.",
+		"Link":     u.downloadLink(token),
+	}); err != nil {
+		log.Printf("failed send export mail %d %v", record.ID, err)
+	}
+}
+
+// writeArchive puts one json file per kind of data plus a csv of scores into a zip.
+func (u *exportUseCase) writeArchive(record entity.DataExport) (string, error) {
+	user, err := u.authRepo.GetUserById(record.UserID)
+	if err != nil {
+		return "", err
+	}
+	quizzes, err := u.exportRepo.GetQuizzesByCreator(record.UserID)
+	if err != nil {
+		return "", err
+	}
+	submissions, err := u.exportRepo.GetSubmissionsByUser(record.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	// the export must not leak answer keys the quiz would not show its takers
+	for _, s := range submissions {
+		if s.QuizCreator == nil || *s.QuizCreator != record.UserID {
+			applyRevealPolicy(s.Answers, s.RevealPolicy)
+		}
+	}
+
+	if err := os.MkdirAll(u.dir, 0o700); err != nil {
+		return "", err
+	}
+	suffix, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(u.dir, fmt.Sprintf("export-%d-%s.zip", record.ID, suffix[:12]))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", profileResponse(user)},
+		{"quizzes.json", quizzes},
+		{"submissions.json", submissions},
+	}
+	for _, f := range files {
+		w, err := archive.Create(f.name)
+		if err != nil {
+			return path, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return path, err
+		}
+	}
+
+	w, err := archive.Create("scores.csv")
+	if err != nil {
+		return path, err
+	}
+	if err := writeScores(w, submissions); err != nil {
+		return path, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return path, err
+	}
+	return path, file.Close()
+}
+
+func writeScores(w io.Writer, submissions []dto.ExportSubmission) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"submission_id", "quiz_id", "quiz_title", "score", "submitted_at"}); err != nil {
+		return err
+	}
+	for _, s := range submissions {
+		if err := out.Write([]string{
+			strconv.FormatUint(uint64(s.ID), 10),
+			strconv.FormatUint(uint64(s.QuizID), 10),
+			csvSafe(s.QuizTitle),
+			strconv.FormatFloat(float64(s.Score), 'f', -1, 32),
+			s.CreatedAt.Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// csvSafe stops spreadsheet apps from running a title as a formula.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@") {
+		return "'" + value
+	}
+	return value
+}
+
+func (u *exportUseCase) downloadLink(token string) string {
+	return buildLink(u.baseURL, "/export/download", map[string]string{"token": token})
+}
+
+func exportResponse(record *entity.DataExport) *dto.ExportResponse {
+	return &dto.ExportResponse{
+		ID:          record.ID,
+		Status:      record.Status,
+		CreatedAt:   record.CreatedAt,
+		CompletedAt: record.CompletedAt,
+		ExpiresAt:   record.ExpiresAt,
+	}
+}
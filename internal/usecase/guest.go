@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"log"
+)
+
+type GuestUseCase interface {
+	IssueToken() (*dto.GuestTokenResponse, error)
+
+	//public quiz
+	GetPublicQuizzes() ([]dto.JustQuizResponse, error)
+	GetPublicQuiz(quizId uint) (*dto.QuizResponseWithQS, error)
+
+	//guest attempt
+	CreateSubmission(input *dto.Submission) (*dto.SubmissionResponse, error)
+	GetSubmissions(guestId string) ([]dto.JustSubmissionResponse, error)
+	Claim(userId uint, guestToken string) (*dto.ClaimGuestResponse, error)
+}
+
+type guestUseCase struct {
+	quizRepo       repository.QuizRepository
+	submissionRepo repository.SubmissionRepository
+	revocationRepo repository.RevocationRepository
+}
+
+func NewGuestUseCase(quizRepo repository.QuizRepository, submissionRepo repository.SubmissionRepository, revocationRepo repository.RevocationRepository) GuestUseCase {
+	return &guestUseCase{quizRepo, submissionRepo, revocationRepo}
+}
+
+func (u *guestUseCase) IssueToken() (*dto.GuestTokenResponse, error) {
+	guestId, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := helper.GenerateJWTGuest(guestId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.GuestTokenResponse{
+		GuestToken: token,
+		ExpiresIn:  int64(helper.GuestTokenTTL.Seconds()),
+	}, nil
+}
+
+func (u *guestUseCase) GetPublicQuizzes() ([]dto.JustQuizResponse, error) {
+	return u.quizRepo.GetPublicQuizzes()
+}
+
+// GetPublicQuiz always returns the player view, private quizzes look like they do not exist.
+func (u *guestUseCase) GetPublicQuiz(quizId uint) (*dto.QuizResponseWithQS, error) {
+	result, err := u.quizRepo.GetPublicQuizById(quizId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Question {
+		hideAnswerKey(&result.Question[i])
+	}
+
+	return result, nil
+}
+
+func (u *guestUseCase) CreateSubmission(input *dto.Submission) (*dto.SubmissionResponse, error) {
+	if err := u.requirePublic(input.QuizID); err != nil {
+		return nil, err
+	}
+
+	submission, err := u.submissionRepo.CreateSubmission(input)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := u.quizRepo.GetRevealPolicy(submission.QuizID)
+	if err != nil {
+		return nil, err
+	}
+	applyRevealPolicy(submission.Answers, policy)
+
+	return submission, nil
+}
+
+func (u *guestUseCase) GetSubmissions(guestId string) ([]dto.JustSubmissionResponse, error) {
+	return u.submissionRepo.GetSubmissionsByGuest(guestId)
+}
+
+func (u *guestUseCase) Claim(userId uint, guestToken string) (*dto.ClaimGuestResponse, error) {
+	claimed, err := claimGuestAttempts(u.submissionRepo, u.revocationRepo, userId, guestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ClaimGuestResponse{Claimed: claimed}, nil
+}
+
+func (u *guestUseCase) requirePublic(quizId uint) error {
+	public, err := u.quizRepo.IsPublicQuiz(quizId)
+	if err != nil {
+		return err
+	}
+	if !public {
+		return helper.ErrQuizNotFound
+	}
+
+	return nil
+}
+
+// claimGuestAttempts moves the attempts made with a guest token to the user and retires the token.
+func claimGuestAttempts(submissionRepo repository.SubmissionRepository, revocationRepo repository.RevocationRepository, userId uint, guestToken string) (int64, error) {
+	claims, err := helper.ParseJWT(guestToken)
+	if err != nil || claims.Purpose != helper.PurposeGuest || claims.Subject == "" || claims.ID == "" {
+		return 0, helper.ErrInvalidToken
+	}
+
+	revoked, err := revocationRepo.IsTokenRevoked(claims.ID)
+	if err != nil {
+		return 0, err
+	}
+	if revoked {
+		return 0, helper.ErrInvalidToken
+	}
+
+	claimed, err := submissionRepo.ClaimGuestSubmissions(claims.Subject, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return claimed, revocationRepo.RevokeToken(claims.ID, claims.ExpiresAt.Time)
+}
+
+// claimGuestOnLogin is best effort, a stale guest token must not fail the login or signup it came with.
+func claimGuestOnLogin(submissionRepo repository.SubmissionRepository, revocationRepo repository.RevocationRepository, userId uint, guestToken string) {
+	if guestToken == "" {
+		return
+	}
+
+	if _, err := claimGuestAttempts(submissionRepo, revocationRepo, userId, guestToken); err != nil && err != helper.ErrInvalidToken {
+		log.Printf("failed claim guest attempts user %d %v", userId, err)
+	}
+}
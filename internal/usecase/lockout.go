@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/mailer"
+	"time"
+)
+
+// loginLockout counts failed passwords and failed 2fa codes per email and per ip in the database,
+// so a new login challenge, a restart or another instance does not start the count over.
+type loginLockout struct {
+	attemptRepo repository.LoginAttemptRepository
+	tokenRepo   repository.TokenRepository
+	mailer      mailer.Mailer
+	baseURL     string
+}
+
+func newLoginLockout(attemptRepo repository.LoginAttemptRepository, tokenRepo repository.TokenRepository, mailer mailer.Mailer, baseURL string) *loginLockout {
+	return &loginLockout{attemptRepo, tokenRepo, mailer, baseURL}
+}
+
+// check locks the email after loginLockAfter failures, and before that makes
+// every extra failure wait twice as long as the previous one.
+func (l *loginLockout) check(email, ip string) error {
+	since := time.Now().Add(-loginFailureWindow)
+
+	byIP, err := l.attemptRepo.CountFailedByIP(ip, since)
+	if err != nil {
+		return err
+	}
+	if byIP >= loginMaxFailuresByIP {
+		return helper.ErrLoginThrottled
+	}
+
+	byEmail, last, err := l.attemptRepo.CountFailedByEmail(email, since)
+	if err != nil {
+		return err
+	}
+	if byEmail >= loginLockAfter {
+		return helper.ErrLoginThrottled
+	}
+	if byEmail >= loginDelayAfter && last != nil {
+		delay := time.Second << (byEmail - loginDelayAfter)
+		if delay > loginMaxDelay {
+			delay = loginMaxDelay
+		}
+		if time.Since(*last) < delay {
+			return helper.ErrLoginThrottled
+		}
+	}
+
+	return nil
+}
+
+// recordFailure saves the failure, when it locks a real account the owner gets an unlock link.
+func (l *loginLockout) recordFailure(email, ip string, user *entity.User) error {
+	if err := l.attemptRepo.RecordFailedLogin(email, ip); err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	total, _, err := l.attemptRepo.CountFailedByEmail(email, time.Now().Add(-loginFailureWindow))
+	if err != nil {
+		return err
+	}
+	if total != loginLockAfter {
+		return nil
+	}
+
+	if err := l.tokenRepo.InvalidateUserTokens(user.ID, entity.TokenPurposeUnlock); err != nil {
+		return err
+	}
+	token, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	record := entity.UserToken{
+		UserID:    user.ID,
+		Purpose:   entity.TokenPurposeUnlock,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(unlockTTL),
+	}
+	if err := l.tokenRepo.CreateUserToken(&record); err != nil {
+		return err
+	}
+
+	return sendMail(l.mailer, user.Email, "Your Account Was Locked", mailer.TemplateNotification, map[string]any{
+		"Username": user.Username,
+		"Message":  "Ada terlalu banyak percobaan login yang gagal, jadi akun kamu dikunci sementara selama 15 menit. Kalau itu kamu, buka link ini untuk membuka kunci sekarang. Kalau bukan, sebaiknya ganti password.",
+		"Link":     buildLink(l.baseURL, "/login/unlock", map[string]string{"token": token}),
+	})
+}
+
+func (l *loginLockout) clear(email string) error {
+	return l.attemptRepo.ClearFailedByEmail(email)
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"api_quiz/utils/mailer"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+func sendMail(m mailer.Mailer, to, subject, template string, data map[string]any) error {
+	msg, err := mailer.Render(to, subject, template, data)
+	if err != nil {
+		return err
+	}
+	if err := m.Send(msg); err != nil {
+		return fmt.Errorf("failed send email: %w", err)
+	}
+	return nil
+}
+
+// passwordResetLink opens the reset page of the frontend, which posts the token to /password/reset.
+// Without a frontend the mail only carries the token.
+func passwordResetLink(frontendURL, token string) string {
+	if frontendURL == "" {
+		return ""
+	}
+	return buildLink(frontendURL, "/reset-password", map[string]string{"token": token})
+}
+
+// buildLink joins a path and query params onto the public base url.
+func buildLink(baseURL, path string, query map[string]string) string {
+	values := url.Values{}
+	for k, v := range query {
+		values.Set(k, v)
+	}
+
+	link := strings.TrimRight(baseURL, "/") + path
+	if len(values) > 0 {
+		link += "?" + values.Encode()
+	}
+	return link
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/oidc"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type OAuthUseCase interface {
+	LoginURL(provider string) (string, error)
+	Callback(provider, state, code string, client dto.ClientInfo) (*dto.LoginResponse, error)
+}
+
+const oauthStateTTL = 10 * time.Minute
+
+type oauthUseCase struct {
+	authRepo     repository.AuthRepository
+	tokenRepo    repository.TokenRepository
+	sessionRepo  repository.SessionRepository
+	identityRepo repository.IdentityRepository
+	providers    map[string]*oidc.Provider
+}
+
+func NewOAuthUseCase(authRepo repository.AuthRepository, tokenRepo repository.TokenRepository, sessionRepo repository.SessionRepository, identityRepo repository.IdentityRepository, providers map[string]*oidc.Provider) OAuthUseCase {
+	return &oauthUseCase{authRepo, tokenRepo, sessionRepo, identityRepo, providers}
+}
+
+func (u *oauthUseCase) LoginURL(provider string) (string, error) {
+	p, ok := u.providers[provider]
+	if !ok {
+		return "", helper.ErrUnknownProvider
+	}
+
+	state, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	record := entity.OAuthState{
+		StateHash:    helper.HashToken(state),
+		Provider:     provider,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	}
+	if err := u.identityRepo.SaveState(&record); err != nil {
+		return "", err
+	}
+
+	authURL, err := p.AuthCodeURL(state, nonce, oidc.S256Challenge(verifier))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", helper.ErrOAuthFailed, err)
+	}
+	return authURL, nil
+}
+
+func (u *oauthUseCase) Callback(provider, state, code string, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	p, ok := u.providers[provider]
+	if !ok {
+		return nil, helper.ErrUnknownProvider
+	}
+
+	record, err := u.identityRepo.ConsumeState(helper.HashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if record.Provider != provider {
+		return nil, helper.ErrInvalidOAuthState
+	}
+
+	token, err := p.Exchange(code, record.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", helper.ErrOAuthFailed, err)
+	}
+
+	claims, err := p.VerifyIDToken(token.IDToken, record.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", helper.ErrOAuthFailed, err)
+	}
+
+	user, err := u.resolveUser(provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return loginResponse(u.tokenRepo, u.sessionRepo, user, client)
+}
+
+// resolveUser finds the linked account, links an existing account with the same verified email, or signs up a new one.
+// Unverified accounts are never linked, whoever registered them may not own the email and would keep their password.
+func (u *oauthUseCase) resolveUser(provider string, claims *oidc.IDTokenClaims) (*entity.User, error) {
+	identity, err := u.identityRepo.GetIdentity(provider, claims.Subject)
+	if err == nil {
+		return u.authRepo.GetUserById(identity.UserID)
+	}
+	if err != helper.ErrIdentityNotFound {
+		return nil, err
+	}
+
+	if claims.Email == "" || !emailVerified(provider, claims) {
+		return nil, helper.ErrProviderEmailUnverify
+	}
+
+	newIdentity := entity.UserIdentity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+
+	user, err := u.authRepo.GetUserByEmail(claims.Email)
+	if err == nil {
+		if !user.IsVerified {
+			return nil, helper.ErrUnverifiedAccount
+		}
+
+		newIdentity.UserID = user.ID
+		if err := u.identityRepo.CreateIdentity(&newIdentity); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if err != helper.ErrUserNotFound {
+		return nil, err
+	}
+
+	// a deleted account still holds the email until it is purged
+	taken, err := u.authRepo.EmailExists(claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, helper.ErrEmailTaken
+	}
+
+	username, err := u.uniqueUsername(claims.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	// social accounts get an unusable random password, they can still set one through /password/forgot
+	randomPassword, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashed, err := helper.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	newUser := entity.User{
+		Email:      claims.Email,
+		Password:   hashed,
+		Username:   username,
+		Role:       entity.RoleStudent,
+		IsVerified: true,
+	}
+	if err := u.identityRepo.CreateUserWithIdentity(&newUser, &newIdentity); err != nil {
+		return nil, err
+	}
+
+	return &newUser, nil
+}
+
+// emailVerified trusts email_verified, except for microsoft where users can set the email of an account
+// themselves, there only the xms_edov optional claim (enable it in the app registration) counts.
+func emailVerified(provider string, claims *oidc.IDTokenClaims) bool {
+	if provider == "microsoft" {
+		return claims.EmailDomainVerified
+	}
+	return claims.EmailVerified
+}
+
+var usernameCleaner = regexp.MustCompile(`[^a-zA-Z0-9_.]`)
+
+func (u *oauthUseCase) uniqueUsername(email string) (string, error) {
+	base := usernameCleaner.ReplaceAllString(strings.Split(email, "@")[0], "")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for range 5 {
+		taken, err := u.identityRepo.IsUsernameTaken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s_%04d", base, n.Int64())
+	}
+
+	return "", fmt.Errorf("failed generate unique username for %s", email)
+}
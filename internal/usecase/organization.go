@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"strings"
+)
+
+type OrganizationUseCase interface {
+	CreateOrganization(actor dto.Actor, input *dto.CreateOrganization) (*dto.OrganizationResponse, error)
+	GetMyOrganizations(userId uint) ([]dto.OrganizationResponse, error)
+
+	//member
+	GetMembers(actor dto.Actor, orgId uint) ([]dto.MemberResponse, error)
+	AddMember(actor dto.Actor, orgId uint, input *dto.AddMember) (*dto.MemberResponse, error)
+	UpdateMember(actor dto.Actor, orgId, userId uint, input *dto.UpdateMember) error
+	RemoveMember(actor dto.Actor, orgId, userId uint) error
+
+	//active organization
+	SwitchOrganization(userId, sessionId, orgId uint) (*dto.TokenResponse, error)
+}
+
+type organizationUseCase struct {
+	orgRepo  repository.OrganizationRepository
+	authRepo repository.AuthRepository
+}
+
+func NewOrganizationUseCase(orgRepo repository.OrganizationRepository, authRepo repository.AuthRepository) OrganizationUseCase {
+	return &organizationUseCase{orgRepo, authRepo}
+}
+
+// CreateOrganization is for admins of the deployment, the creator becomes the first owner.
+func (u *organizationUseCase) CreateOrganization(actor dto.Actor, input *dto.CreateOrganization) (*dto.OrganizationResponse, error) {
+	if !isAdmin(actor) {
+		return nil, helper.ErrUnauhorized
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	input.Slug = strings.ToLower(strings.TrimSpace(input.Slug))
+	if input.Name == "" || len(input.Name) > 100 || !helper.IsValidSlug(input.Slug) {
+		return nil, helper.ErrInvalidOrg
+	}
+
+	exists, err := u.orgRepo.SlugExists(input.Slug)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, helper.ErrOrgSlugTaken
+	}
+
+	org := entity.Organization{Name: input.Name, Slug: input.Slug}
+	if err := u.orgRepo.CreateOrganization(&org, actor.UserID); err != nil {
+		return nil, err
+	}
+
+	return &dto.OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		Slug:      org.Slug,
+		Role:      entity.OrgRoleOwner,
+		CreatedAt: org.CreatedAt,
+	}, nil
+}
+
+func (u *organizationUseCase) GetMyOrganizations(userId uint) ([]dto.OrganizationResponse, error) {
+	return u.orgRepo.GetUserOrganizations(userId)
+}
+
+func (u *organizationUseCase) GetMembers(actor dto.Actor, orgId uint) ([]dto.MemberResponse, error) {
+	if _, err := u.orgRepo.GetMembership(orgId, actor.UserID); err != nil {
+		return nil, err
+	}
+
+	return u.orgRepo.GetMembers(orgId)
+}
+
+// AddMember adds an existing account by email, only owners can hand out the owner role.
+func (u *organizationUseCase) AddMember(actor dto.Actor, orgId uint, input *dto.AddMember) (*dto.MemberResponse, error) {
+	if input.Role == "" {
+		input.Role = entity.OrgRoleMember
+	}
+	if !isValidOrgRole(input.Role) {
+		return nil, helper.ErrInvalidOrgRole
+	}
+
+	manager, err := u.manager(actor, orgId)
+	if err != nil {
+		return nil, err
+	}
+	if input.Role == entity.OrgRoleOwner && manager.Role != entity.OrgRoleOwner {
+		return nil, helper.ErrUnauhorized
+	}
+
+	user, err := u.authRepo.GetUserByEmail(strings.TrimSpace(input.Email))
+	if err != nil {
+		return nil, err
+	}
+
+	member := entity.Membership{OrganizationID: orgId, UserID: user.ID, Role: input.Role}
+	if err := u.orgRepo.AddMember(&member); err != nil {
+		return nil, err
+	}
+
+	return &dto.MemberResponse{
+		UserID:      user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Email:       user.Email,
+		Role:        member.Role,
+		JoinedAt:    member.CreatedAt,
+	}, nil
+}
+
+// UpdateMember lets owners and admins change roles, owners are only promoted or demoted by owners.
+func (u *organizationUseCase) UpdateMember(actor dto.Actor, orgId, userId uint, input *dto.UpdateMember) error {
+	if !isValidOrgRole(input.Role) {
+		return helper.ErrInvalidOrgRole
+	}
+
+	manager, err := u.manager(actor, orgId)
+	if err != nil {
+		return err
+	}
+
+	target, err := u.orgRepo.GetMembership(orgId, userId)
+	if err != nil {
+		return err
+	}
+	if (input.Role == entity.OrgRoleOwner || target.Role == entity.OrgRoleOwner) && manager.Role != entity.OrgRoleOwner {
+		return helper.ErrUnauhorized
+	}
+	if target.Role == entity.OrgRoleOwner && input.Role != entity.OrgRoleOwner {
+		if err := u.keepOwner(orgId); err != nil {
+			return err
+		}
+	}
+
+	return u.orgRepo.UpdateMemberRole(orgId, userId, input.Role)
+}
+
+// RemoveMember is for owners and admins, any member can also leave on their own.
+func (u *organizationUseCase) RemoveMember(actor dto.Actor, orgId, userId uint) error {
+	self, err := u.orgRepo.GetMembership(orgId, actor.UserID)
+	if err != nil {
+		return err
+	}
+
+	target, err := u.orgRepo.GetMembership(orgId, userId)
+	if err != nil {
+		return err
+	}
+
+	if userId != actor.UserID {
+		if self.Role != entity.OrgRoleOwner && self.Role != entity.OrgRoleAdmin {
+			return helper.ErrUnauhorized
+		}
+		if target.Role == entity.OrgRoleOwner && self.Role != entity.OrgRoleOwner {
+			return helper.ErrUnauhorized
+		}
+	}
+	if target.Role == entity.OrgRoleOwner {
+		if err := u.keepOwner(orgId); err != nil {
+			return err
+		}
+	}
+
+	return u.orgRepo.RemoveMember(orgId, userId)
+}
+
+// SwitchOrganization moves the current session to another organization and returns an access token for it,
+// the refresh token stays the same because it follows the session.
+func (u *organizationUseCase) SwitchOrganization(userId, sessionId, orgId uint) (*dto.TokenResponse, error) {
+	user, err := u.authRepo.GetUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var active *uint
+	if orgId != 0 {
+		if _, err := u.orgRepo.GetMembership(orgId, userId); err != nil {
+			return nil, err
+		}
+		active = &orgId
+	}
+
+	if err := u.orgRepo.SetActiveOrganization(userId, sessionId, active); err != nil {
+		return nil, err
+	}
+
+	return tokenResponse(user, "", &entity.Session{ID: sessionId, OrganizationID: active})
+}
+
+// manager returns the membership of the actor when they can manage members of the organization.
+func (u *organizationUseCase) manager(actor dto.Actor, orgId uint) (*entity.Membership, error) {
+	member, err := u.orgRepo.GetMembership(orgId, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != entity.OrgRoleOwner && member.Role != entity.OrgRoleAdmin {
+		return nil, helper.ErrUnauhorized
+	}
+
+	return member, nil
+}
+
+func (u *organizationUseCase) keepOwner(orgId uint) error {
+	owners, err := u.orgRepo.CountOwners(orgId)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return helper.ErrLastOwner
+	}
+
+	return nil
+}
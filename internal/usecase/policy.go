@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+)
+
+func isAdmin(actor dto.Actor) bool {
+	return actor.Role == entity.RoleAdmin
+}
+
+// isOrgAdmin is true for owners and admins of the organization the actor is working in.
+func isOrgAdmin(actor dto.Actor) bool {
+	return actor.OrgID != 0 && (actor.OrgRole == entity.OrgRoleOwner || actor.OrgRole == entity.OrgRoleAdmin)
+}
+
+// canModerate covers everything inside the actor's organization, every lookup is already scoped to it.
+func canModerate(actor dto.Actor) bool {
+	return isAdmin(actor) || isOrgAdmin(actor)
+}
+
+func isValidOrgRole(role string) bool {
+	return role == entity.OrgRoleOwner || role == entity.OrgRoleAdmin || role == entity.OrgRoleMember
+}
+
+func canCreateQuiz(actor dto.Actor) bool {
+	return actor.Role == entity.RoleAdmin || actor.Role == entity.RoleCreator
+}
+
+func isValidRevealPolicy(policy string) bool {
+	return policy == entity.RevealAfterSubmit || policy == entity.RevealCorrectnessOnly || policy == entity.RevealNever
+}
+
+func isValidUnansweredPolicy(policy string) bool {
+	return policy == entity.UnansweredRequired || policy == entity.UnansweredZero || policy == entity.UnansweredPenalty
+}
+
+func isValidRole(role string) bool {
+	return role == entity.RoleAdmin || role == entity.RoleCreator || role == entity.RoleStudent
+}
+
+// canManageQuiz lets admins moderate every quiz of the organization while creators only touch their own.
+func canManageQuiz(quizRepo repository.QuizRepository, actor dto.Actor, quizId uint) error {
+	creatorId, err := quizRepo.GetQuizCreator(actor.OrgID, quizId)
+	if err != nil {
+		return err
+	}
+	if canModerate(actor) {
+		return nil
+	}
+	if actor.Role != entity.RoleCreator || creatorId == nil || *creatorId != actor.UserID {
+		return helper.ErrUnauhorized
+	}
+
+	return nil
+}
+
+// canAccessQuiz hides quizzes restricted to a class from everyone outside it, as if they did not exist.
+func canAccessQuiz(quizRepo repository.QuizRepository, actor dto.Actor, quizId uint) error {
+	if canModerate(actor) {
+		return nil
+	}
+
+	ok, err := quizRepo.CanAccessQuiz(actor.UserID, quizId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return helper.ErrQuizNotFound
+	}
+
+	return nil
+}
+
+// canManageClass allows the teacher of the class and moderators of its organization.
+func canManageClass(actor dto.Actor, class *entity.Class) bool {
+	return canModerate(actor) || class.TeacherID == actor.UserID
+}
+
+// canViewSubmission allows the taker, the creator of the quiz and admins.
+func canViewSubmission(quizRepo repository.QuizRepository, actor dto.Actor, ownerId, quizId uint) error {
+	if canModerate(actor) || ownerId == actor.UserID {
+		return nil
+	}
+
+	isCreator, err := quizRepo.IsCreator(actor.UserID, quizId)
+	if err != nil {
+		return err
+	}
+	if !isCreator {
+		return helper.ErrUnauhorized
+	}
+
+	return nil
+}
+
+// canSeeAnswerKey is true for admins and the creator of the quiz, everyone else gets the player view.
+func canSeeAnswerKey(quizRepo repository.QuizRepository, actor dto.Actor, quizId uint) (bool, error) {
+	if canModerate(actor) {
+		return true, nil
+	}
+
+	return quizRepo.IsCreator(actor.UserID, quizId)
+}
+
+// applyRevealPolicy strips the correctness details a taker is not allowed to see yet.
+func applyRevealPolicy(answers []dto.SubmissionAnswerResponse, policy string) {
+	for i := range answers {
+		switch policy {
+		case entity.RevealAfterSubmit:
+		case entity.RevealCorrectnessOnly:
+			answers[i].CorrectAnswer = nil
+			answers[i].CorrectAnswers = nil
+		default:
+			answers[i].CorrectAnswer = nil
+			answers[i].CorrectAnswers = nil
+			answers[i].IsCorrect = nil
+			answers[i].Points = nil
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/utils/helper"
+	"sort"
+	"strings"
+)
+
+const maxAnswers = 5
+
+func isValidQuestionType(questionType string) bool {
+	switch questionType {
+	case entity.QuestionSingleChoice, entity.QuestionMultiSelect, entity.QuestionTrueFalse, entity.QuestionShortText,
+		entity.QuestionNumeric, entity.QuestionOrdering, entity.QuestionMatching:
+		return true
+	}
+	return false
+}
+
+func isValidMatchMode(mode string) bool {
+	return mode == entity.MatchExact || mode == entity.MatchCaseInsensitive || mode == entity.MatchRegex
+}
+
+// normalizeAnswers drops the fields a type does not use, every answer of a short text or numeric question is accepted.
+func normalizeAnswers(questionType string, answers []dto.Answer) {
+	for i := range answers {
+		if questionType == entity.QuestionShortText || questionType == entity.QuestionNumeric {
+			answers[i].IsCorrect = true
+		}
+		if questionType != entity.QuestionOrdering {
+			answers[i].Rank = 0
+		}
+		if questionType != entity.QuestionMatching {
+			answers[i].MatchText = ""
+		}
+		answers[i].MatchText = strings.TrimSpace(answers[i].MatchText)
+	}
+}
+
+// validateQuestion checks the whole answer set against the rules of the question type,
+// it runs after every change so adding, editing or removing one answer can not break a question.
+func validateQuestion(questionType, matchMode string, tolerance float64, answers []dto.Answer) error {
+	if !isValidQuestionType(questionType) {
+		return helper.ErrInvalidQuestionType
+	}
+	if !isValidMatchMode(matchMode) {
+		return helper.ErrInvalidMatchMode
+	}
+	if tolerance < 0 {
+		return helper.ErrInvalidTolerance
+	}
+
+	correct := 0
+	for _, ans := range answers {
+		if ans.IsCorrect {
+			correct++
+		}
+	}
+
+	switch questionType {
+	case entity.QuestionTrueFalse:
+		if len(answers) != 2 || correct != 1 {
+			return helper.ErrTrueFalseAnswer
+		}
+		return nil
+	case entity.QuestionShortText:
+		if len(answers) == 0 || len(answers) > maxAnswers {
+			return helper.ErrAcceptedAnswer
+		}
+		if matchMode == entity.MatchRegex {
+			for _, ans := range answers {
+				if _, err := helper.CompileAcceptedAnswer(ans.Text); err != nil {
+					return helper.ErrInvalidRegex
+				}
+			}
+		}
+		return nil
+	case entity.QuestionNumeric:
+		if len(answers) != 1 {
+			return helper.ErrNumericAnswer
+		}
+		if _, err := helper.ParseNumber(answers[0].Text); err != nil {
+			return helper.ErrNumericAnswer
+		}
+		return nil
+	}
+
+	if len(answers) < 2 {
+		return helper.ErrAnswerNotEnough
+	}
+	if len(answers) > maxAnswers {
+		return helper.ErrToomuchAnswer
+	}
+
+	switch questionType {
+	case entity.QuestionSingleChoice:
+		if correct != 1 {
+			return helper.ErrCorrectAnswer
+		}
+	case entity.QuestionMultiSelect:
+		if correct == 0 {
+			return helper.ErrNoCorrectAnswer
+		}
+	case entity.QuestionOrdering:
+		ranks := make(map[int]bool)
+		for _, ans := range answers {
+			if ans.Rank <= 0 || ranks[ans.Rank] {
+				return helper.ErrInvalidRank
+			}
+			ranks[ans.Rank] = true
+		}
+	case entity.QuestionMatching:
+		matches := make(map[string]bool)
+		for _, ans := range answers {
+			key := strings.ToLower(ans.MatchText)
+			if key == "" || matches[key] {
+				return helper.ErrInvalidMatch
+			}
+			matches[key] = true
+		}
+	}
+
+	return nil
+}
+
+// validateScoring allows a penalty above the points, a wrong guess may cost more than a right one earns.
+func validateScoring(points, penalty float64) error {
+	if points <= 0 {
+		return helper.ErrInvalidPoints
+	}
+	if penalty < 0 {
+		return helper.ErrInvalidPenalty
+	}
+	return nil
+}
+
+// answersOf turns the stored answers of a question back into input so a change can be validated on the whole set.
+func answersOf(question *dto.QuestionResponse) []dto.Answer {
+	answers := make([]dto.Answer, len(question.Answer))
+	for i, ans := range question.Answer {
+		answers[i] = dto.Answer{ID: ans.ID, QuestionID: ans.QuestionID, Text: ans.Text}
+		if ans.IsCorrect != nil {
+			answers[i].IsCorrect = *ans.IsCorrect
+		}
+		if ans.Rank != nil {
+			answers[i].Rank = *ans.Rank
+		}
+		if ans.MatchText != nil {
+			answers[i].MatchText = *ans.MatchText
+		}
+	}
+	return answers
+}
+
+// hideAnswerKey prepares a question for the player view, accepted answers of short text and numeric
+// questions are the key itself and matching items only keep their options, sorted so the pairs do not show.
+// Ordering items are usually entered in the correct sequence, so they are sorted by text and renumbered.
+func hideAnswerKey(question *dto.QuestionResponse) {
+	switch question.Type {
+	case entity.QuestionShortText, entity.QuestionNumeric:
+		question.Answer = []dto.AnswerResponse{}
+	case entity.QuestionOrdering:
+		sort.SliceStable(question.Answer, func(i, j int) bool {
+			return strings.ToLower(question.Answer[i].Text) < strings.ToLower(question.Answer[j].Text)
+		})
+		for i := range question.Answer {
+			question.Answer[i].Position = i + 1
+		}
+	case entity.QuestionMatching:
+		question.MatchOptions = make([]string, 0, len(question.Answer))
+		for _, ans := range question.Answer {
+			if ans.MatchText != nil {
+				question.MatchOptions = append(question.MatchOptions, *ans.MatchText)
+			}
+		}
+		sort.Strings(question.MatchOptions)
+	}
+
+	for i := range question.Answer {
+		question.Answer[i].IsCorrect = nil
+		question.Answer[i].Rank = nil
+		question.Answer[i].MatchText = nil
+	}
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"api_quiz/dto"
+	"api_quiz/entity"
 	"api_quiz/internal/repository"
 	"api_quiz/utils/helper"
 )
@@ -9,43 +10,92 @@ import (
 type QuizUseCase interface {
 
 	//quiz
-	GetAllQuiz() ([]dto.JustQuizResponse, error)
-	GetQuizFromId(quizId uint) (*dto.QuizResponseWithQS, error)
-	CreateQuiz(input *dto.Quiz) (*dto.JustQuizResponse, error)
-	UpdateQuiz(input *dto.UpdatedQuiz, userId uint) (*dto.JustQuizResponse, error)
-	DeleteQuiz(userId, quizId uint) error
+	GetAllQuiz(actor dto.Actor) ([]dto.JustQuizResponse, error)
+	GetQuizFromId(actor dto.Actor, quizId uint) (*dto.QuizResponseWithQS, error)
+	CreateQuiz(input *dto.Quiz, actor dto.Actor) (*dto.JustQuizResponse, error)
+	UpdateQuiz(input *dto.UpdatedQuiz, actor dto.Actor) (*dto.JustQuizResponse, error)
+	DeleteQuiz(actor dto.Actor, quizId uint) error
 
 	//question
-	GetQuestionAnswerByQuizId(quizId uint) ([]dto.QuestionResponse, error)
-	GetQuestionById(questionId, quizId uint) (*dto.QuestionResponse, error)
-	CreateQuestionAndAnswer(inputQuestion *dto.Question, userId uint) (*dto.QuestionResponse, error)
-	UpdateQuestion(input *dto.QuestionUpdate, userId uint) (*dto.JustQuestionResponse, error)
-	DeleteQuestion(questionId, quizId, userId uint) error
+	GetQuestionAnswerByQuizId(actor dto.Actor, quizId uint) ([]dto.QuestionResponse, error)
+	GetQuestionById(actor dto.Actor, questionId, quizId uint) (*dto.QuestionResponse, error)
+	CreateQuestionAndAnswer(inputQuestion *dto.Question, actor dto.Actor) (*dto.QuestionResponse, error)
+	UpdateQuestion(input *dto.QuestionUpdate, actor dto.Actor) (*dto.JustQuestionResponse, error)
+	DeleteQuestion(questionId, quizId uint, actor dto.Actor) error
+	ReorderQuestions(actor dto.Actor, quizId uint, questionIds []uint) ([]dto.QuestionResponse, error)
 
 	//answer
-	GetAnswerByQuestionId(questionId uint) ([]dto.AnswerResponse, error)
-	UpdateAnswer(userId, quizId uint, input dto.Answer) ([]dto.AnswerResponse, error)
-	DeleteAnswer(answerId, questionId, quizId, userId uint) error
-	AddAnswer(userId, quizId uint, input []dto.Answer) ([]dto.AnswerResponse, error)
+	GetAnswerByQuestionId(actor dto.Actor, questionId uint) ([]dto.AnswerResponse, error)
+	UpdateAnswer(actor dto.Actor, quizId uint, input dto.Answer) ([]dto.AnswerResponse, error)
+	DeleteAnswer(answerId, questionId, quizId uint, actor dto.Actor) error
+	AddAnswer(actor dto.Actor, quizId uint, input []dto.Answer) ([]dto.AnswerResponse, error)
+	ReorderAnswers(actor dto.Actor, quizId, questionId uint, answerIds []uint) ([]dto.AnswerResponse, error)
 }
 
 type quizUseCase struct {
-	quizRepo repository.QuizRepository
+	quizRepo  repository.QuizRepository
+	classRepo repository.ClassRepository
 }
 
-func NewQuizUseCase(quizRepo repository.QuizRepository) QuizUseCase {
-	return &quizUseCase{quizRepo}
+func NewQuizUseCase(quizRepo repository.QuizRepository, classRepo repository.ClassRepository) QuizUseCase {
+	return &quizUseCase{quizRepo, classRepo}
 }
 
-func (u *quizUseCase) GetAllQuiz() ([]dto.JustQuizResponse, error) {
-	return u.quizRepo.GetAllQuiz()
+// GetAllQuiz leaves out quizzes of classes the user is not part of, moderators see the whole organization.
+func (u *quizUseCase) GetAllQuiz(actor dto.Actor) ([]dto.JustQuizResponse, error) {
+	if canModerate(actor) {
+		return u.quizRepo.GetAllQuiz(actor.OrgID)
+	}
+
+	return u.quizRepo.GetQuizzesVisibleTo(actor.UserID, actor.OrgID)
 }
 
-func (u *quizUseCase) GetQuizFromId(quizId uint) (*dto.QuizResponseWithQS, error) {
-	return u.quizRepo.GetQuizById(quizId)
+func (u *quizUseCase) GetQuizFromId(actor dto.Actor, quizId uint) (*dto.QuizResponseWithQS, error) {
+	if err := canAccessQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
+	}
+
+	result, err := u.quizRepo.GetQuizById(actor.OrgID, quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	fullKey, err := canSeeAnswerKey(u.quizRepo, actor, quizId)
+	if err != nil {
+		return nil, err
+	}
+	if !fullKey {
+		for i := range result.Question {
+			hideAnswerKey(&result.Question[i])
+		}
+	}
+
+	return result, nil
 }
 
-func (u *quizUseCase) CreateQuiz(input *dto.Quiz) (*dto.JustQuizResponse, error) {
+func (u *quizUseCase) CreateQuiz(input *dto.Quiz, actor dto.Actor) (*dto.JustQuizResponse, error) {
+	if !canCreateQuiz(actor) {
+		return nil, helper.ErrForbidden
+	}
+	if input.RevealPolicy == "" {
+		input.RevealPolicy = entity.RevealAfterSubmit
+	}
+	if !isValidRevealPolicy(input.RevealPolicy) {
+		return nil, helper.ErrInvalidRevealPolicy
+	}
+	if input.UnansweredPolicy == "" {
+		input.UnansweredPolicy = entity.UnansweredRequired
+	}
+	if !isValidUnansweredPolicy(input.UnansweredPolicy) {
+		return nil, helper.ErrInvalidUnanswered
+	}
+
+	if err := u.checkClass(actor, input.ClassID); err != nil {
+		return nil, err
+	}
+
+	input.Creator = actor.UserID
+	input.OrgID = actor.OrgID
 	result, err := u.quizRepo.CreateQuiz(input)
 	if err != nil {
 		return nil, err
@@ -53,16 +103,21 @@ func (u *quizUseCase) CreateQuiz(input *dto.Quiz) (*dto.JustQuizResponse, error)
 	return result, nil
 }
 
-func (u *quizUseCase) UpdateQuiz(input *dto.UpdatedQuiz, userId uint) (*dto.JustQuizResponse, error) {
-	valid, err := u.quizRepo.IsCreator(userId, input.ID)
-	if err != nil {
+func (u *quizUseCase) UpdateQuiz(input *dto.UpdatedQuiz, actor dto.Actor) (*dto.JustQuizResponse, error) {
+	if err := canManageQuiz(u.quizRepo, actor, input.ID); err != nil {
 		return nil, err
 	}
-	if !valid {
-		return nil, helper.ErrUnauhorized
+	if input.RevealPolicy != "" && !isValidRevealPolicy(input.RevealPolicy) {
+		return nil, helper.ErrInvalidRevealPolicy
+	}
+	if input.UnansweredPolicy != "" && !isValidUnansweredPolicy(input.UnansweredPolicy) {
+		return nil, helper.ErrInvalidUnanswered
+	}
+	if err := u.checkClass(actor, input.ClassID); err != nil {
+		return nil, err
 	}
 
-	result, err := u.quizRepo.UpdateQuiz(input, userId)
+	result, err := u.quizRepo.UpdateQuiz(input)
 	if err != nil {
 		return nil, err
 	}
@@ -70,14 +125,10 @@ func (u *quizUseCase) UpdateQuiz(input *dto.UpdatedQuiz, userId uint) (*dto.Just
 	return result, nil
 }
 
-func (u *quizUseCase) DeleteQuiz(userId, quizId uint) error {
-	valid, err := u.quizRepo.IsCreator(userId, quizId)
-	if err != nil {
+func (u *quizUseCase) DeleteQuiz(actor dto.Actor, quizId uint) error {
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
 		return err
 	}
-	if !valid {
-		return helper.ErrUnauhorized
-	}
 
 	if err := u.quizRepo.DeleteQuiz(quizId); err != nil {
 		return err
@@ -87,22 +138,70 @@ func (u *quizUseCase) DeleteQuiz(userId, quizId uint) error {
 }
 
 // question
-func (u *quizUseCase) GetQuestionAnswerByQuizId(quizId uint) ([]dto.QuestionResponse, error) {
-	return u.quizRepo.GetQuestionAnswerByQuizId(quizId)
-}
+func (u *quizUseCase) GetQuestionAnswerByQuizId(actor dto.Actor, quizId uint) ([]dto.QuestionResponse, error) {
+	if err := canAccessQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
+	}
+
+	result, err := u.quizRepo.GetQuestionAnswerByQuizId(actor.OrgID, quizId)
+	if err != nil {
+		return nil, err
+	}
 
-func (u *quizUseCase) GetQuestionById(questionId, quizId uint) (*dto.QuestionResponse, error) {
-	return u.quizRepo.GetQuestionById(questionId, quizId)
+	fullKey, err := canSeeAnswerKey(u.quizRepo, actor, quizId)
+	if err != nil {
+		return nil, err
+	}
+	if !fullKey {
+		for i := range result {
+			hideAnswerKey(&result[i])
+		}
+	}
+
+	return result, nil
 }
 
-func (u *quizUseCase) CreateQuestionAndAnswer(inputQuestion *dto.Question, userId uint) (*dto.QuestionResponse, error) {
+func (u *quizUseCase) GetQuestionById(actor dto.Actor, questionId, quizId uint) (*dto.QuestionResponse, error) {
+	if err := canAccessQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
+	}
+
+	result, err := u.quizRepo.GetQuestionById(actor.OrgID, questionId, quizId)
+	if err != nil {
+		return nil, err
+	}
 
-	valid, err := u.quizRepo.IsCreator(userId, inputQuestion.QuizID)
+	fullKey, err := canSeeAnswerKey(u.quizRepo, actor, quizId)
 	if err != nil {
 		return nil, err
 	}
-	if !valid {
-		return nil, helper.ErrUnauhorized
+	if !fullKey {
+		hideAnswerKey(result)
+	}
+
+	return result, nil
+}
+
+func (u *quizUseCase) CreateQuestionAndAnswer(inputQuestion *dto.Question, actor dto.Actor) (*dto.QuestionResponse, error) {
+	if err := canManageQuiz(u.quizRepo, actor, inputQuestion.QuizID); err != nil {
+		return nil, err
+	}
+	if inputQuestion.Type == "" {
+		inputQuestion.Type = entity.QuestionSingleChoice
+	}
+	if inputQuestion.MatchMode == "" {
+		inputQuestion.MatchMode = entity.MatchExact
+	}
+	if inputQuestion.Points == 0 {
+		inputQuestion.Points = 1
+	}
+	if err := validateScoring(inputQuestion.Points, inputQuestion.Penalty); err != nil {
+		return nil, err
+	}
+
+	normalizeAnswers(inputQuestion.Type, inputQuestion.Answers)
+	if err := validateQuestion(inputQuestion.Type, inputQuestion.MatchMode, inputQuestion.Tolerance, inputQuestion.Answers); err != nil {
+		return nil, err
 	}
 
 	result, err := u.quizRepo.CreateQuestionAndAnswer(inputQuestion)
@@ -114,13 +213,35 @@ func (u *quizUseCase) CreateQuestionAndAnswer(inputQuestion *dto.Question, userI
 
 }
 
-func (u *quizUseCase) UpdateQuestion(input *dto.QuestionUpdate, userId uint) (*dto.JustQuestionResponse, error) {
-	valid, err := u.quizRepo.IsCreator(userId, input.QuizID)
+func (u *quizUseCase) UpdateQuestion(input *dto.QuestionUpdate, actor dto.Actor) (*dto.JustQuestionResponse, error) {
+	if err := canManageQuiz(u.quizRepo, actor, input.QuizID); err != nil {
+		return nil, err
+	}
+
+	question, err := u.quizRepo.GetQuestionById(actor.OrgID, input.ID, input.QuizID)
 	if err != nil {
 		return nil, err
 	}
-	if !valid {
-		return nil, helper.ErrUnauhorized
+	if input.MatchMode == "" {
+		input.MatchMode = question.MatchMode
+	}
+	if input.MatchMode == "" {
+		input.MatchMode = entity.MatchExact
+	}
+	if input.Tolerance == nil {
+		input.Tolerance = &question.Tolerance
+	}
+	if input.Points == nil {
+		input.Points = &question.Points
+	}
+	if input.Penalty == nil {
+		input.Penalty = &question.Penalty
+	}
+	if err := validateScoring(*input.Points, *input.Penalty); err != nil {
+		return nil, err
+	}
+	if err := validateQuestion(question.Type, input.MatchMode, *input.Tolerance, answersOf(question)); err != nil {
+		return nil, err
 	}
 
 	result, err := u.quizRepo.UpdateQuestion(input)
@@ -130,14 +251,10 @@ func (u *quizUseCase) UpdateQuestion(input *dto.QuestionUpdate, userId uint) (*d
 	return result, err
 }
 
-func (u *quizUseCase) DeleteQuestion(questionId, quizId, userId uint) error {
-	valid, err := u.quizRepo.IsCreator(userId, quizId)
-	if err != nil {
+func (u *quizUseCase) DeleteQuestion(questionId, quizId uint, actor dto.Actor) error {
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
 		return err
 	}
-	if !valid {
-		return helper.ErrUnauhorized
-	}
 
 	if err := u.quizRepo.DeleteQuestion(quizId, questionId); err != nil {
 		return err
@@ -146,21 +263,68 @@ func (u *quizUseCase) DeleteQuestion(questionId, quizId, userId uint) error {
 	return nil
 }
 
+// ReorderQuestions returns the questions in their new order.
+func (u *quizUseCase) ReorderQuestions(actor dto.Actor, quizId uint, questionIds []uint) ([]dto.QuestionResponse, error) {
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
+	}
+
+	if err := u.quizRepo.ReorderQuestions(quizId, questionIds); err != nil {
+		return nil, err
+	}
+
+	return u.quizRepo.GetQuestionAnswerByQuizId(actor.OrgID, quizId)
+}
+
 // answer
-func (u *quizUseCase) GetAnswerByQuestionId(questionId uint) ([]dto.AnswerResponse, error) {
-	return u.quizRepo.GetAnswerByQuestionId(questionId)
+func (u *quizUseCase) GetAnswerByQuestionId(actor dto.Actor, questionId uint) ([]dto.AnswerResponse, error) {
+	quizId, err := u.quizRepo.GetQuizIdByQuestionId(actor.OrgID, questionId)
+	if err != nil {
+		return nil, err
+	}
+	if err := canAccessQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
+	}
+
+	result, err := u.quizRepo.GetQuestionById(actor.OrgID, questionId, quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	fullKey, err := canSeeAnswerKey(u.quizRepo, actor, quizId)
+	if err != nil {
+		return nil, err
+	}
+	if !fullKey {
+		hideAnswerKey(result)
+	}
+
+	return result.Answer, nil
 }
 
-func (u *quizUseCase) UpdateAnswer(userId, quizId uint, input dto.Answer) ([]dto.AnswerResponse, error) {
-	valid, err := u.quizRepo.IsCreator(userId, quizId)
+func (u *quizUseCase) UpdateAnswer(actor dto.Actor, quizId uint, input dto.Answer) ([]dto.AnswerResponse, error) {
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
+	}
+
+	question, err := u.quizRepo.GetQuestionById(actor.OrgID, input.QuestionID, quizId)
 	if err != nil {
 		return nil, err
 	}
-	if !valid {
-		return nil, helper.ErrUnauhorized
+	answers := answersOf(question)
+	index := answerIndex(answers, input.ID)
+	if index < 0 {
+		return nil, helper.ErrAnswerNotFound
 	}
 
-	result, err := u.quizRepo.UpdateAnswer(input)
+	changed := []dto.Answer{input}
+	normalizeAnswers(question.Type, changed)
+	answers[index] = changed[0]
+	if err := validateQuestion(question.Type, question.MatchMode, question.Tolerance, answers); err != nil {
+		return nil, err
+	}
+
+	result, err := u.quizRepo.UpdateAnswer(changed[0])
 
 	if err != nil {
 		return nil, err
@@ -169,13 +333,21 @@ func (u *quizUseCase) UpdateAnswer(userId, quizId uint, input dto.Answer) ([]dto
 	return result, nil
 }
 
-func (u *quizUseCase) AddAnswer(userId, quizId uint, input []dto.Answer) ([]dto.AnswerResponse, error) {
-	valid, err := u.quizRepo.IsCreator(userId, quizId)
+func (u *quizUseCase) AddAnswer(actor dto.Actor, quizId uint, input []dto.Answer) ([]dto.AnswerResponse, error) {
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
+	}
+	if len(input) == 0 {
+		return nil, helper.ErrAnswerNotEnough
+	}
+
+	question, err := u.quizRepo.GetQuestionById(actor.OrgID, input[0].QuestionID, quizId)
 	if err != nil {
 		return nil, err
 	}
-	if !valid {
-		return nil, helper.ErrUnauhorized
+	normalizeAnswers(question.Type, input)
+	if err := validateQuestion(question.Type, question.MatchMode, question.Tolerance, append(answersOf(question), input...)); err != nil {
+		return nil, err
 	}
 
 	result, err := u.quizRepo.AddAnswer(input)
@@ -185,13 +357,22 @@ func (u *quizUseCase) AddAnswer(userId, quizId uint, input []dto.Answer) ([]dto.
 
 	return result, nil
 }
-func (u *quizUseCase) DeleteAnswer(answerId, questionId, quizId, userId uint) error {
-	valid, err := u.quizRepo.IsCreator(userId, quizId)
+func (u *quizUseCase) DeleteAnswer(answerId, questionId, quizId uint, actor dto.Actor) error {
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
+		return err
+	}
+
+	question, err := u.quizRepo.GetQuestionById(actor.OrgID, questionId, quizId)
 	if err != nil {
 		return err
 	}
-	if !valid {
-		return helper.ErrUnauhorized
+	answers := answersOf(question)
+	index := answerIndex(answers, answerId)
+	if index < 0 {
+		return helper.ErrAnswerNotFound
+	}
+	if err := validateQuestion(question.Type, question.MatchMode, question.Tolerance, append(answers[:index], answers[index+1:]...)); err != nil {
+		return err
 	}
 
 	if err := u.quizRepo.DeleteAnswer(answerId, questionId); err != nil {
@@ -200,3 +381,45 @@ func (u *quizUseCase) DeleteAnswer(answerId, questionId, quizId, userId uint) er
 
 	return nil
 }
+
+func (u *quizUseCase) ReorderAnswers(actor dto.Actor, quizId, questionId uint, answerIds []uint) ([]dto.AnswerResponse, error) {
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
+	}
+	if _, err := u.quizRepo.GetQuestionById(actor.OrgID, questionId, quizId); err != nil {
+		return nil, err
+	}
+
+	result, err := u.quizRepo.ReorderAnswers(questionId, answerIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func answerIndex(answers []dto.Answer, answerId uint) int {
+	for i, ans := range answers {
+		if ans.ID == answerId {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkClass makes sure a quiz is only restricted to a class the actor can manage, 0 lifts the restriction.
+func (u *quizUseCase) checkClass(actor dto.Actor, classId *uint) error {
+	if classId == nil || *classId == 0 {
+		return nil
+	}
+
+	class, err := u.classRepo.GetClassById(actor.OrgID, *classId)
+	if err != nil {
+		return err
+	}
+	if !canManageClass(actor, class) {
+		return helper.ErrUnauhorized
+	}
+
+	return nil
+}
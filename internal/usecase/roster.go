@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/mailer"
+	"encoding/csv"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RosterUseCase interface {
+	ImportRoster(actor dto.Actor, csvData io.Reader) (*dto.RosterImportReport, error)
+}
+
+const (
+	maxRosterRows = 1000
+	invitationTTL = 7 * 24 * time.Hour
+)
+
+const (
+	rosterCreated  = "created"
+	rosterExisting = "existing"
+	rosterInvited  = "invited"
+	rosterFailed   = "failed"
+)
+
+type rosterUseCase struct {
+	rosterRepo  repository.RosterRepository
+	classRepo   repository.ClassRepository
+	mailer      mailer.Mailer
+	baseURL     string
+	frontendURL string
+}
+
+func NewRosterUseCase(rosterRepo repository.RosterRepository, classRepo repository.ClassRepository, mailer mailer.Mailer, baseURL, frontendURL string) RosterUseCase {
+	return &rosterUseCase{rosterRepo, classRepo, mailer, baseURL, frontendURL}
+}
+
+// invitation keeps the plain tokens of a new account until the invitation is mailed.
+type invitation struct {
+	verifyToken   string
+	passwordToken string
+}
+
+// ImportRoster reads email, username and an optional class (id or name) per row.
+// New emails get an unverified student account and an invitation, existing accounts are only put in the
+// organization and class when the actor can already see them, admins see every account and everyone else
+// only members of the organization. Other accounts are asked by email to join on their own and their row
+// reads invited like a new account, so the import can not be used to find out which emails are registered.
+func (u *rosterUseCase) ImportRoster(actor dto.Actor, csvData io.Reader) (*dto.RosterImportReport, error) {
+	if !canModerate(actor) && actor.Role != entity.RoleCreator {
+		return nil, helper.ErrForbidden
+	}
+
+	records, firstRow, err := readRoster(csvData)
+	if err != nil {
+		return nil, err
+	}
+
+	// nobody knows this password, invited users set their own with the token in the invitation
+	randomPassword, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	placeholder, err := helper.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.RosterImportReport{Rows: make([]dto.RosterRowResult, len(records))}
+	var entries []repository.RosterEntry
+	var entryRows []int
+	var invitations []invitation
+
+	seen := make(map[string]bool)
+	classes := make(map[string]classLookup)
+	for i, record := range records {
+		row := &report.Rows[i]
+		row.Row = firstRow + i
+
+		entry, err := u.rosterEntry(actor, record, row, seen, classes)
+		if err != nil {
+			row.Status = rosterFailed
+			row.Error = err.Error()
+			continue
+		}
+
+		entry.Password = placeholder
+		invite, err := newInvitation(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, *entry)
+		entryRows = append(entryRows, i)
+		invitations = append(invitations, invite)
+	}
+
+	if len(entries) > 0 {
+		if err := u.rosterRepo.ImportRoster(actor.OrgID, isAdmin(actor), entries); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, entry := range entries {
+		row := &report.Rows[entryRows[i]]
+		switch {
+		case entry.Err != nil:
+			row.Status = rosterFailed
+			row.Error = entry.Err.Error()
+		case entry.Created:
+			row.Status = rosterInvited
+			if isAdmin(actor) {
+				row.Status = rosterCreated
+				row.UserID = entry.User.ID
+			}
+			if err := u.sendInvitation(entry.User, invitations[i]); err != nil {
+				log.Printf("failed send invitation to user %d %v", entry.User.ID, err)
+				row.Error = "invitation email failed, resend the verification later"
+			}
+		case entry.Hidden:
+			row.Status = rosterInvited
+			// deleted accounts still holding the email have nobody to mail, the join code only
+			// finds classes of the organization the student works in so members of none get the link
+			joinCode := ""
+			if actor.OrgID == 0 {
+				joinCode = classes[row.Class].joinCode
+			}
+			if entry.User != nil {
+				if err := u.sendJoinLink(entry.User, joinCode); err != nil {
+					log.Printf("failed send join link to user %d %v", entry.User.ID, err)
+				}
+			}
+		default:
+			row.Status = rosterExisting
+			row.UserID = entry.User.ID
+		}
+	}
+
+	for _, row := range report.Rows {
+		switch row.Status {
+		case rosterCreated:
+			report.Created++
+		case rosterExisting:
+			report.Existing++
+		case rosterInvited:
+			report.Invited++
+		default:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// readRoster returns the rows without the optional header and the line number of the first row.
+func readRoster(csvData io.Reader) ([][]string, int, error) {
+	reader := csv.NewReader(csvData)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, 0, helper.ErrInvalidCSV
+	}
+
+	firstRow := 1
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "email") {
+		records = records[1:]
+		firstRow = 2
+	}
+	if len(records) == 0 {
+		return nil, 0, helper.ErrEmptyRoster
+	}
+	if len(records) > maxRosterRows {
+		return nil, 0, helper.ErrTooManyRows
+	}
+
+	return records, firstRow, nil
+}
+
+type classLookup struct {
+	id       uint
+	joinCode string
+	err      error
+}
+
+func (u *rosterUseCase) rosterEntry(actor dto.Actor, record []string, row *dto.RosterRowResult, seen map[string]bool, classes map[string]classLookup) (*repository.RosterEntry, error) {
+	for len(record) < 3 {
+		record = append(record, "")
+	}
+	row.Email = strings.ToLower(strings.TrimSpace(record[0]))
+	row.Username = strings.TrimSpace(record[1])
+	row.Class = strings.TrimSpace(record[2])
+
+	if row.Email == "" || row.Username == "" {
+		return nil, helper.ErrIncompleteRow
+	}
+	if !helper.IsValidEmail(row.Email) {
+		return nil, helper.ErrInvalidEmail
+	}
+	if !helper.IsValidUsername(row.Username) {
+		return nil, helper.ErrInvalidUsername
+	}
+
+	emailKey, usernameKey := "email:"+row.Email, "username:"+strings.ToLower(row.Username)
+	if seen[emailKey] || seen[usernameKey] {
+		return nil, helper.ErrDuplicateRow
+	}
+	seen[emailKey], seen[usernameKey] = true, true
+
+	entry := &repository.RosterEntry{Email: row.Email, Username: row.Username}
+	if row.Class != "" {
+		lookup, ok := classes[row.Class]
+		if !ok {
+			var class *entity.Class
+			class, lookup.err = u.resolveClass(actor, row.Class)
+			if lookup.err == nil {
+				lookup.id, lookup.joinCode = class.ID, class.JoinCode
+			}
+			classes[row.Class] = lookup
+		}
+		if lookup.err != nil {
+			return nil, lookup.err
+		}
+		entry.ClassID = lookup.id
+	}
+
+	return entry, nil
+}
+
+// resolveClass accepts a class id or an exact class name, only classes the actor can manage count.
+func (u *rosterUseCase) resolveClass(actor dto.Actor, value string) (*entity.Class, error) {
+	var candidates []entity.Class
+	if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+		class, err := u.classRepo.GetClassById(actor.OrgID, uint(id))
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, *class)
+	} else {
+		found, err := u.classRepo.GetClassesByName(actor.OrgID, value)
+		if err != nil {
+			return nil, err
+		}
+		candidates = found
+	}
+
+	var manageable []entity.Class
+	for _, class := range candidates {
+		if canManageClass(actor, &class) {
+			manageable = append(manageable, class)
+		}
+	}
+
+	switch len(manageable) {
+	case 0:
+		if len(candidates) > 0 {
+			return nil, helper.ErrUnauhorized
+		}
+		return nil, helper.ErrClassNotFound
+	case 1:
+		return &manageable[0], nil
+	default:
+		return nil, helper.ErrAmbiguousClass
+	}
+}
+
+// newInvitation prepares a verification token and a token to set the first password, both stored with the account.
+func newInvitation(entry *repository.RosterEntry) (invitation, error) {
+	verifyToken, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return invitation{}, err
+	}
+	passwordToken, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return invitation{}, err
+	}
+
+	expiresAt := time.Now().Add(invitationTTL)
+	entry.Tokens = []entity.UserToken{
+		{Purpose: entity.TokenPurposeVerification, TokenHash: helper.HashToken(verifyToken), ExpiresAt: expiresAt},
+		{Purpose: entity.TokenPurposePasswordReset, TokenHash: helper.HashToken(passwordToken), ExpiresAt: expiresAt},
+	}
+
+	return invitation{verifyToken, passwordToken}, nil
+}
+
+func (u *rosterUseCase) sendInvitation(user *entity.User, invite invitation) error {
+	return sendMail(u.mailer, user.Email, "You Have Been Invited", mailer.TemplateInvitation, map[string]any{
+		"Username":     user.Username,
+		"Link":         buildLink(u.baseURL, "/verification", map[string]string{"token": invite.verifyToken}),
+		"Token":        invite.passwordToken,
+		"PasswordLink": passwordResetLink(u.frontendURL, invite.passwordToken),
+		"ExpiresIn":    "7 hari",
+	})
+}
+
+// sendJoinLink asks an existing account to join the class itself, without a join code only an org admin can add it.
+func (u *rosterUseCase) sendJoinLink(user *entity.User, joinCode string) error {
+	data := map[string]any{
+		"Username": user.Username,
+		"Message":  "Guru kamu ingin menambahkan akun kamu. Minta admin organisasi untuk menambahkan kamu sebagai anggota.",
+	}
+	if joinCode != "" {
+		data["Message"] = "Guru kamu mengundang kamu ke kelasnya. Buka link di bawah kalau kamu mau bergabung."
+		data["Link"] = buildLink(u.baseURL, "/class/join", map[string]string{"code": joinCode})
+	}
+	return sendMail(u.mailer, user.Email, "You Have Been Invited", mailer.TemplateNotification, data)
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/internal/repository"
+)
+
+type SessionUseCase interface {
+	GetSessions(userId, currentId uint) ([]dto.SessionResponse, error)
+	RevokeSession(userId, id uint) error
+	RevokeOtherSessions(userId, currentId uint) error
+}
+
+type sessionUseCase struct {
+	sessionRepo repository.SessionRepository
+	tokenRepo   repository.TokenRepository
+}
+
+func NewSessionUseCase(sessionRepo repository.SessionRepository, tokenRepo repository.TokenRepository) SessionUseCase {
+	return &sessionUseCase{sessionRepo, tokenRepo}
+}
+
+func (u *sessionUseCase) GetSessions(userId, currentId uint) ([]dto.SessionResponse, error) {
+	sessions, err := u.sessionRepo.GetActiveSessions(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.SessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		response = append(response, dto.SessionResponse{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			Current:    s.ID == currentId,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+		})
+	}
+
+	return response, nil
+}
+
+func (u *sessionUseCase) RevokeSession(userId, id uint) error {
+	return revokeSession(u.tokenRepo, u.sessionRepo, userId, id)
+}
+
+// RevokeOtherSessions signs out every other device, the session making the call stays logged in.
+func (u *sessionUseCase) RevokeOtherSessions(userId, currentId uint) error {
+	sessions, err := u.sessionRepo.RevokeOtherSessions(userId, currentId)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		if err := u.tokenRepo.RevokeRefreshTokenFamily(s.FamilyID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -7,11 +7,13 @@ import (
 )
 
 type SubmissionUseCase interface {
-	GetAllSubmission() ([]dto.JustSubmissionResponse, error)
-	GetSubmissionById(submissionId uint) (*dto.SubmissionResponse, error)
-	CreateSubmission(input *dto.Submission) (*dto.SubmissionResponse, error)
-	UpdateSubmision(input *dto.SubmissionUpdate, userId uint) (*dto.JustSubmissionResponse, error)
-	DeleteSubmision(submissionId, userId uint) error
+	GetAllSubmission(actor dto.Actor) ([]dto.JustSubmissionResponse, error)
+	GetMySubmission(actor dto.Actor) ([]dto.JustSubmissionResponse, error)
+	GetSubmissionByQuizId(actor dto.Actor, quizId uint) ([]dto.JustSubmissionResponse, error)
+	GetSubmissionById(actor dto.Actor, submissionId uint) (*dto.SubmissionResponse, error)
+	CreateSubmission(input *dto.Submission, actor dto.Actor) (*dto.SubmissionResponse, error)
+	UpdateSubmision(input *dto.SubmissionUpdate, actor dto.Actor) (*dto.JustSubmissionResponse, error)
+	DeleteSubmision(submissionId uint, actor dto.Actor) error
 }
 
 type submissionUseCase struct {
@@ -23,53 +25,104 @@ func NewSubmissionUseCase(submissionRepo repository.SubmissionRepository, quizRe
 	return &submissionUseCase{submissionRepo, quizRepo}
 }
 
-func (u *submissionUseCase) GetAllSubmission() ([]dto.JustSubmissionResponse, error) {
-	return u.submissionRepo.GetAllSubmission()
+// GetAllSubmission lists everything in the organization for moderators, other users only see what canViewSubmission allows.
+func (u *submissionUseCase) GetAllSubmission(actor dto.Actor) ([]dto.JustSubmissionResponse, error) {
+	if canModerate(actor) {
+		return u.submissionRepo.GetAllSubmission(actor.OrgID)
+	}
+
+	return u.submissionRepo.GetSubmissionsVisibleTo(actor.UserID, actor.OrgID)
 }
 
-func (u *submissionUseCase) GetSubmissionById(submissionId uint) (*dto.SubmissionResponse, error) {
-	return u.submissionRepo.GetSubmissionById(submissionId)
+func (u *submissionUseCase) GetMySubmission(actor dto.Actor) ([]dto.JustSubmissionResponse, error) {
+	return u.submissionRepo.GetSubmissionsByUser(actor.UserID, actor.OrgID)
 }
 
-func (u *submissionUseCase) CreateSubmission(input *dto.Submission) (*dto.SubmissionResponse, error) {
-	return u.submissionRepo.CreateSubmission(input)
+func (u *submissionUseCase) GetSubmissionByQuizId(actor dto.Actor, quizId uint) ([]dto.JustSubmissionResponse, error) {
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
+	}
+
+	return u.submissionRepo.GetSubmissionsByQuiz(quizId)
 }
 
-func (u *submissionUseCase) UpdateSubmision(input *dto.SubmissionUpdate, userId uint) (*dto.JustSubmissionResponse, error) {
-	quizId, err := u.submissionRepo.GetQuizIdFromSubmisionId(input.SubmissionID)
+func (u *submissionUseCase) GetSubmissionById(actor dto.Actor, submissionId uint) (*dto.SubmissionResponse, error) {
+	submission, err := u.submissionRepo.GetSubmissionById(actor.OrgID, submissionId)
 	if err != nil {
 		return nil, err
 	}
-	if quizId == 0 {
-		return nil, helper.ErrQuizNotFound
+
+	if err := canViewSubmission(u.quizRepo, actor, submission.UserID, submission.QuizID); err != nil {
+		return nil, err
 	}
 
-	valid, err := u.quizRepo.IsCreator(userId, quizId)
+	fullKey, err := canSeeAnswerKey(u.quizRepo, actor, submission.QuizID)
 	if err != nil {
 		return nil, err
 	}
-	if !valid {
-		return nil, helper.ErrUnauhorized
+	if !fullKey {
+		if err := u.revealForTaker(submission); err != nil {
+			return nil, err
+		}
 	}
 
-	return u.submissionRepo.UpdateSubmission(input)
+	return submission, nil
 }
 
-func (u *submissionUseCase) DeleteSubmision(submissionId, userId uint) error {
-	quizId, err := u.submissionRepo.GetQuizIdFromSubmisionId(submissionId)
+func (u *submissionUseCase) CreateSubmission(input *dto.Submission, actor dto.Actor) (*dto.SubmissionResponse, error) {
+	if err := canAccessQuiz(u.quizRepo, actor, input.QuizID); err != nil {
+		return nil, err
+	}
+
+	submission, err := u.submissionRepo.CreateSubmission(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.revealForTaker(submission); err != nil {
+		return nil, err
+	}
+
+	return submission, nil
+}
+
+func (u *submissionUseCase) revealForTaker(submission *dto.SubmissionResponse) error {
+	policy, err := u.quizRepo.GetRevealPolicy(submission.QuizID)
 	if err != nil {
 		return err
 	}
+
+	applyRevealPolicy(submission.Answers, policy)
+	return nil
+}
+
+func (u *submissionUseCase) UpdateSubmision(input *dto.SubmissionUpdate, actor dto.Actor) (*dto.JustSubmissionResponse, error) {
+	quizId, err := u.submissionRepo.GetQuizIdFromSubmisionId(actor.OrgID, input.SubmissionID)
+	if err != nil {
+		return nil, err
+	}
 	if quizId == 0 {
-		return helper.ErrQuizNotFound
+		return nil, helper.ErrQuizNotFound
+	}
+
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
+		return nil, err
 	}
 
-	valid, err := u.quizRepo.IsCreator(userId, quizId)
+	return u.submissionRepo.UpdateSubmission(input)
+}
+
+func (u *submissionUseCase) DeleteSubmision(submissionId uint, actor dto.Actor) error {
+	quizId, err := u.submissionRepo.GetQuizIdFromSubmisionId(actor.OrgID, submissionId)
 	if err != nil {
 		return err
 	}
-	if !valid {
-		return helper.ErrUnauhorized
+	if quizId == 0 {
+		return helper.ErrQuizNotFound
+	}
+
+	if err := canManageQuiz(u.quizRepo, actor, quizId); err != nil {
+		return err
 	}
 
 	return u.submissionRepo.DeleteSubmission(submissionId)
@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/mailer"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+)
+
+type TwoFactorUseCase interface {
+	Enroll(userId uint) (*dto.TwoFactorEnrollResponse, error)
+	Confirm(userId uint, code string) (*dto.RecoveryCodesResponse, error)
+	Disable(userId uint, code string) error
+	RegenerateRecoveryCodes(userId uint, code string) (*dto.RecoveryCodesResponse, error)
+
+	//login
+	VerifyLogin(input *dto.TwoFactorLogin) (*dto.TokenResponse, error)
+}
+
+const recoveryCodeCount = 10
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type twoFactorUseCase struct {
+	authRepo       repository.AuthRepository
+	twoFactorRepo  repository.TwoFactorRepository
+	tokenRepo      repository.TokenRepository
+	sessionRepo    repository.SessionRepository
+	revocationRepo repository.RevocationRepository
+	submissionRepo repository.SubmissionRepository
+	lockout        *loginLockout
+	issuer         string
+}
+
+func NewTwoFactorUseCase(authRepo repository.AuthRepository, twoFactorRepo repository.TwoFactorRepository, tokenRepo repository.TokenRepository, sessionRepo repository.SessionRepository, revocationRepo repository.RevocationRepository, submissionRepo repository.SubmissionRepository, attemptRepo repository.LoginAttemptRepository, mailer mailer.Mailer, baseURL, issuer string) TwoFactorUseCase {
+	return &twoFactorUseCase{
+		authRepo:       authRepo,
+		twoFactorRepo:  twoFactorRepo,
+		tokenRepo:      tokenRepo,
+		sessionRepo:    sessionRepo,
+		revocationRepo: revocationRepo,
+		submissionRepo: submissionRepo,
+		lockout:        newLoginLockout(attemptRepo, tokenRepo, mailer, baseURL),
+		issuer:         issuer,
+	}
+}
+
+// Enroll stores a fresh secret, 2fa is only enforced after Confirm proves the app has it.
+func (u *twoFactorUseCase) Enroll(userId uint) (*dto.TwoFactorEnrollResponse, error) {
+	user, err := u.authRepo.GetUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, helper.ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := helper.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.twoFactorRepo.SaveTOTPSecret(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: helper.TOTPURI(u.issuer, user.Email, secret),
+	}, nil
+}
+
+func (u *twoFactorUseCase) Confirm(userId uint, code string) (*dto.RecoveryCodesResponse, error) {
+	user, err := u.authRepo.GetUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, helper.ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, helper.ErrTOTPNotEnrolled
+	}
+
+	if err := u.checkTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.twoFactorRepo.EnableTOTP(user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (u *twoFactorUseCase) Disable(userId uint, code string) error {
+	user, err := u.enabledUser(userId)
+	if err != nil {
+		return err
+	}
+
+	if err := u.checkCode(user, code); err != nil {
+		return err
+	}
+
+	return u.twoFactorRepo.DisableTOTP(user.ID)
+}
+
+func (u *twoFactorUseCase) RegenerateRecoveryCodes(userId uint, code string) (*dto.RecoveryCodesResponse, error) {
+	user, err := u.enabledUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.checkTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.twoFactorRepo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// VerifyLogin trades a challenge from Login plus a totp or recovery code for real tokens.
+// The challenge is single use, wrong codes count towards the same lockout as wrong passwords
+// so asking for a new challenge does not give more guesses.
+func (u *twoFactorUseCase) VerifyLogin(input *dto.TwoFactorLogin) (*dto.TokenResponse, error) {
+	claims, err := helper.ParseJWT(input.ChallengeToken)
+	if err != nil || claims.Purpose != helper.PurposeTwoFactor || claims.ID == "" {
+		return nil, helper.ErrInvalidToken
+	}
+
+	revoked, err := u.revocationRepo.IsTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, helper.ErrInvalidToken
+	}
+
+	user, err := u.enabledUser(claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(user.Email)
+	if err := u.lockout.check(email, input.Client.IP); err != nil {
+		return nil, err
+	}
+	if err := u.checkCode(user, input.Code); err != nil {
+		if err == helper.ErrInvalidTOTPCode {
+			if err := u.lockout.recordFailure(email, input.Client.IP, user); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := u.lockout.clear(email); err != nil {
+		return nil, err
+	}
+	if err := u.revocationRepo.RevokeToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+
+	tokens, err := issueTokens(u.tokenRepo, u.sessionRepo, user, input.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	claimGuestOnLogin(u.submissionRepo, u.revocationRepo, user.ID, input.GuestToken)
+	return tokens, nil
+}
+
+func (u *twoFactorUseCase) enabledUser(userId uint) (*entity.User, error) {
+	user, err := u.authRepo.GetUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, helper.ErrTOTPNotEnabled
+	}
+
+	return user, nil
+}
+
+// checkCode accepts either a 6 digit totp code or one of the recovery codes.
+func (u *twoFactorUseCase) checkCode(user *entity.User, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == 6 {
+		return u.checkTOTP(user, code)
+	}
+
+	return u.twoFactorRepo.UseRecoveryCode(user.ID, helper.HashToken(normalizeRecoveryCode(code)))
+}
+
+func (u *twoFactorUseCase) checkTOTP(user *entity.User, code string) error {
+	step, ok := helper.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return helper.ErrInvalidTOTPCode
+	}
+
+	return u.twoFactorRepo.UseTOTPStep(user.ID, step)
+}
+
+// loginResponse is shared by every login method so none of them can skip the 2fa step.
+func loginResponse(tokenRepo repository.TokenRepository, sessionRepo repository.SessionRepository, user *entity.User, client dto.ClientInfo) (*dto.LoginResponse, error) {
+	if user.TOTPEnabled {
+		challenge, err := helper.GenerateJWTChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	tokens, err := issueTokens(tokenRepo, sessionRepo, user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{TokenResponse: tokens}, nil
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, helper.HashToken(raw))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"api_quiz/dto"
+	"api_quiz/entity"
+	"api_quiz/internal/repository"
+	"api_quiz/utils/helper"
+	"api_quiz/utils/mailer"
+	"strings"
+	"time"
+)
+
+type UserUseCase interface {
+	GetProfile(userId uint) (*dto.ProfileResponse, error)
+	UpdateProfile(userId uint, input *dto.UpdateProfile) (*dto.ProfileResponse, error)
+	ChangePassword(userId uint, input *dto.ChangePassword) error
+
+	//email
+	ChangeEmail(userId uint, input *dto.ChangeEmail) error
+	ConfirmEmailChange(token string) error
+}
+
+const (
+	emailChangeTTL     = 24 * time.Hour
+	emailChangePerHour = 5
+)
+
+type userUseCase struct {
+	authRepo       repository.AuthRepository
+	tokenRepo      repository.TokenRepository
+	revocationRepo repository.RevocationRepository
+	passwordPolicy *helper.PasswordPolicy
+	mailer         mailer.Mailer
+	baseURL        string
+}
+
+func NewUserUseCase(authRepo repository.AuthRepository, tokenRepo repository.TokenRepository, revocationRepo repository.RevocationRepository, passwordPolicy *helper.PasswordPolicy, mailer mailer.Mailer, baseURL string) UserUseCase {
+	return &userUseCase{authRepo, tokenRepo, revocationRepo, passwordPolicy, mailer, baseURL}
+}
+
+func (u *userUseCase) GetProfile(userId uint) (*dto.ProfileResponse, error) {
+	user, err := u.authRepo.GetUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return profileResponse(user), nil
+}
+
+func (u *userUseCase) UpdateProfile(userId uint, input *dto.UpdateProfile) (*dto.ProfileResponse, error) {
+	fields := map[string]any{}
+
+	if input.Username != nil {
+		username := strings.TrimSpace(*input.Username)
+		if !helper.IsValidUsername(username) {
+			return nil, helper.ErrInvalidUsername
+		}
+
+		other, err := u.authRepo.GetUserByUsername(username)
+		if err != nil && err != helper.ErrUserNotFound {
+			return nil, err
+		}
+		if other != nil && other.ID != userId {
+			return nil, helper.ErrUsernameTaken
+		}
+		fields["username"] = username
+	}
+	if input.DisplayName != nil {
+		name := strings.TrimSpace(*input.DisplayName)
+		if len(name) > 100 {
+			return nil, helper.ErrInvalidProfile
+		}
+		fields["display_name"] = name
+	}
+	if input.AvatarURL != nil {
+		avatar := strings.TrimSpace(*input.AvatarURL)
+		if avatar != "" && (len(avatar) > 512 || !helper.IsValidHTTPURL(avatar)) {
+			return nil, helper.ErrInvalidProfile
+		}
+		fields["avatar_url"] = avatar
+	}
+	if input.Locale != nil {
+		if *input.Locale != "" && !helper.IsValidLocale(*input.Locale) {
+			return nil, helper.ErrInvalidProfile
+		}
+		fields["locale"] = *input.Locale
+	}
+	if input.TimeZone != nil {
+		if *input.TimeZone != "" && !helper.IsValidTimeZone(*input.TimeZone) {
+			return nil, helper.ErrInvalidProfile
+		}
+		fields["time_zone"] = *input.TimeZone
+	}
+
+	if err := u.authRepo.UpdateProfile(userId, fields); err != nil {
+		return nil, err
+	}
+
+	return u.GetProfile(userId)
+}
+
+// ChangePassword logs every session out, including the one that made the change.
+func (u *userUseCase) ChangePassword(userId uint, input *dto.ChangePassword) error {
+	user, err := u.authRepo.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	if !helper.ComparePassword(user.Password, input.CurrentPassword) {
+		return helper.ErrWrongPassword
+	}
+	if err := u.passwordPolicy.Check(input.NewPassword, user.Email, user.Username); err != nil {
+		return err
+	}
+
+	hashed, err := helper.HashPassword(input.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := u.authRepo.UpdatePassword(user.ID, hashed); err != nil {
+		return err
+	}
+
+	if err := revokeSessions(u.tokenRepo, u.revocationRepo, user.ID); err != nil {
+		return err
+	}
+
+	return sendMail(u.mailer, user.Email, "Your Password Was Changed", mailer.TemplateNotification, map[string]any{
+		"Username": user.Username,
+		"Message":  "Password akun kamu baru saja diganti dan semua sesi login sudah dikeluarkan. Kalau ini bukan kamu, segera reset password.",
+	})
+}
+
+// ChangeEmail keeps the current email until the link sent to the new address is opened.
+func (u *userUseCase) ChangeEmail(userId uint, input *dto.ChangeEmail) error {
+	newEmail := strings.TrimSpace(input.NewEmail)
+	if !helper.IsValidEmail(newEmail) {
+		return helper.ErrInvalidEmail
+	}
+
+	user, err := u.authRepo.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	if !helper.ComparePassword(user.Password, input.Password) {
+		return helper.ErrWrongPassword
+	}
+	if strings.EqualFold(user.Email, newEmail) {
+		return helper.ErrSameEmail
+	}
+	if err := u.ensureEmailFree(newEmail); err != nil {
+		return err
+	}
+
+	lastHour, err := u.tokenRepo.CountUserTokensSince(user.ID, entity.TokenPurposeEmailChange, time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if lastHour >= emailChangePerHour {
+		return helper.ErrTooManyRequests
+	}
+
+	if err := u.tokenRepo.InvalidateUserTokens(user.ID, entity.TokenPurposeEmailChange); err != nil {
+		return err
+	}
+	if err := u.authRepo.SetPendingEmail(user.ID, newEmail); err != nil {
+		return err
+	}
+
+	token, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	record := entity.UserToken{
+		UserID:    user.ID,
+		Purpose:   entity.TokenPurposeEmailChange,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+	}
+	if err := u.tokenRepo.CreateUserToken(&record); err != nil {
+		return err
+	}
+
+	if err := sendMail(u.mailer, newEmail, "Confirm Your New Email", mailer.TemplateNotification, map[string]any{
+		"Username": user.Username,
+		"Message":  "Buka link di bawah untuk memakai email ini di akun kamu (berlaku 24 jam).",
+		"Link":     buildLink(u.baseURL, "/email/confirm", map[string]string{"token": token}),
+	}); err != nil {
+		return err
+	}
+
+	return sendMail(u.mailer, user.Email, "Email Change Requested", mailer.TemplateNotification, map[string]any{
+		"Username": user.Username,
+		"Message":  "Ada permintaan untuk mengganti email akun kamu ke " + newEmail + ". Email lama tetap dipakai sampai alamat baru dikonfirmasi. Kalau ini bukan kamu, segera ganti password.",
+	})
+}
+
+func (u *userUseCase) ConfirmEmailChange(token string) error {
+	record, err := u.tokenRepo.ConsumeUserToken(helper.HashToken(token), entity.TokenPurposeEmailChange)
+	if err != nil {
+		return err
+	}
+
+	user, err := u.authRepo.GetUserById(record.UserID)
+	if err != nil {
+		return err
+	}
+	if user.PendingEmail == "" {
+		return helper.ErrNoPendingEmail
+	}
+
+	// someone may have registered the address while the link was waiting
+	if err := u.ensureEmailFree(user.PendingEmail); err != nil {
+		return err
+	}
+
+	return u.authRepo.ConfirmEmailChange(user.ID, user.PendingEmail)
+}
+
+func (u *userUseCase) ensureEmailFree(email string) error {
+	taken, err := u.authRepo.EmailExists(email)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return helper.ErrEmailTaken
+	}
+
+	return nil
+}
+
+func profileResponse(user *entity.User) *dto.ProfileResponse {
+	return &dto.ProfileResponse{
+		ID:               user.ID,
+		Username:         user.Username,
+		Email:            user.Email,
+		PendingEmail:     user.PendingEmail,
+		DisplayName:      user.DisplayName,
+		AvatarURL:        user.AvatarURL,
+		Locale:           user.Locale,
+		TimeZone:         user.TimeZone,
+		Role:             user.Role,
+		IsVerified:       user.IsVerified,
+		TwoFactorEnabled: user.TOTPEnabled,
+		CreatedAt:        user.CreatedAt,
+	}
+}
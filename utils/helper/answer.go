@@ -0,0 +1,25 @@
+package helper
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseNumber reads a numeric reply, a comma works as decimal separator too ("3,5").
+func ParseNumber(s string) (float64, error) {
+	n, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s), ",", "."), 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, strconv.ErrSyntax
+	}
+	return n, nil
+}
+
+// CompileAcceptedAnswer anchors the pattern so it has to match the whole reply.
+func CompileAcceptedAnswer(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
@@ -0,0 +1,29 @@
+package helper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+const APIKeyPrefix = "aq_"
+
+// GenerateAPIKey returns a key like aq_<id>_<secret>, the first part is kept in plain text so keys can be told apart.
+func GenerateAPIKey() (key string, prefix string, err error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	secret, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix = APIKeyPrefix + hex.EncodeToString(b)
+	return prefix + "_" + secret, prefix, nil
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
@@ -5,18 +5,118 @@ import "errors"
 var (
 
 	//auth
-	ErrUserNotFound = errors.New("uset not found")
-	ErrServerError  = errors.New("server error")
-	ErrInvalidEmail = errors.New("invalid email")
-	ErrUnauhorized  = errors.New("you unauthorized for this action")
+	ErrUserNotFound    = errors.New("uset not found")
+	ErrServerError     = errors.New("server error")
+	ErrInvalidEmail    = errors.New("invalid email")
+	ErrUnauhorized     = errors.New("you unauthorized for this action")
+	ErrAlreadyVerified = errors.New("user already verified")
+	ErrTooManyRequests = errors.New("too many requests, try again later")
+	ErrForbidden       = errors.New("your role is not allowed to do this action")
+	ErrInvalidRole     = errors.New("invalid role")
+	ErrInvalidLogin    = errors.New("invalid email or password")
+	ErrLoginThrottled  = errors.New("too many failed login attempts, try again later")
+
+	//profile
+	ErrUsernameTaken   = errors.New("username already taken")
+	ErrEmailTaken      = errors.New("email already used by another account")
+	ErrInvalidUsername = errors.New("username must be 3-50 characters of letters, numbers, _ or .")
+	ErrInvalidProfile  = errors.New("invalid profile field")
+	ErrWrongPassword   = errors.New("current password is wrong")
+	ErrSameEmail       = errors.New("new email is the same as the current one")
+	ErrNoPendingEmail  = errors.New("no email change is pending")
+	ErrRestoreExpired  = errors.New("account can no longer be restored")
+
+	//password
+	ErrPasswordTooShort  = errors.New("password is too short")
+	ErrPasswordTooLong   = errors.New("password is too long")
+	ErrPasswordTooCommon = errors.New("password is too common, choose another one")
+	ErrPasswordPersonal  = errors.New("password must not contain your email or username")
+
+	//token
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused, all sessions revoked")
+	ErrInvalidToken        = errors.New("invalid or expired token")
+	ErrSessionNotFound     = errors.New("session not found")
+
+	//2fa
+	ErrInvalidTOTPCode    = errors.New("invalid two factor code")
+	ErrTOTPAlreadyEnabled = errors.New("two factor already enabled")
+	ErrTOTPNotEnabled     = errors.New("two factor is not enabled")
+	ErrTOTPNotEnrolled    = errors.New("start two factor enrollment first")
+
+	//api key
+	ErrAPIKeyNotFound   = errors.New("api key not found")
+	ErrInvalidScope     = errors.New("invalid api key scope")
+	ErrTooManyAPIKeys   = errors.New("api key limit reached, revoke an old key first")
+	ErrInvalidKeyName   = errors.New("api key name is required")
+	ErrInvalidKeyExpiry = errors.New("expires_in_days must not be negative")
+
+	//oauth
+	ErrUnknownProvider       = errors.New("unknown login provider")
+	ErrInvalidOAuthState     = errors.New("invalid or expired oauth state")
+	ErrIdentityNotFound      = errors.New("identity not found")
+	ErrProviderEmailUnverify = errors.New("email from provider is not verified")
+	ErrOAuthFailed           = errors.New("failed to complete login with provider")
+	ErrUnverifiedAccount     = errors.New("an unverified account already uses this email, verify it or reset its password first")
 
 	//quiz
-	ErrQuizNotFound     = errors.New("quiz not found")
-	ErrQuestionNotFound = errors.New("question not found")
-	ErrAnswerNotEnough  = errors.New("answer must 2 or more")
-	ErrCorrectAnswer    = errors.New("correct answer just only 1 ")
-	ErrToomuchAnswer    = errors.New("answer max is 5")
+	ErrQuizNotFound        = errors.New("quiz not found")
+	ErrQuestionNotFound    = errors.New("question not found")
+	ErrAnswerNotFound      = errors.New("answer not found")
+	ErrQuestionOrder       = errors.New("question_ids must list every question of the quiz exactly once")
+	ErrAnswerOrder         = errors.New("answer_ids must list every answer of the question exactly once")
+	ErrAnswerNotEnough     = errors.New("answer must 2 or more")
+	ErrCorrectAnswer       = errors.New("correct answer just only 1 ")
+	ErrToomuchAnswer       = errors.New("answer max is 5")
+	ErrInvalidRevealPolicy = errors.New("reveal policy must be after_submit, correctness_only or never")
+	ErrInvalidUnanswered   = errors.New("unanswered policy must be required, zero or penalty")
+	ErrInvalidPoints       = errors.New("points must be above 0")
+	ErrInvalidPenalty      = errors.New("penalty must not be negative")
+
+	//question type
+	ErrInvalidQuestionType = errors.New("question type must be single_choice, multi_select, true_false, short_text, numeric, ordering or matching")
+	ErrNoCorrectAnswer     = errors.New("at least 1 answer must be correct")
+	ErrTrueFalseAnswer     = errors.New("true false question needs exactly 2 answers with 1 correct")
+	ErrAcceptedAnswer      = errors.New("short text question needs 1 to 5 accepted answers")
+	ErrInvalidMatchMode    = errors.New("match mode must be exact, case_insensitive or regex")
+	ErrInvalidRegex        = errors.New("accepted answer is not a valid regex")
+	ErrNumericAnswer       = errors.New("numeric question needs exactly 1 answer that is a number")
+	ErrInvalidTolerance    = errors.New("tolerance must not be negative")
+	ErrInvalidRank         = errors.New("every ordering item needs its own rank above 0")
+	ErrInvalidMatch        = errors.New("every matching item needs its own match")
+
+	//export
+	ErrExportNotFound = errors.New("export not found")
+	ErrExportPending  = errors.New("an export is already being prepared")
+	ErrExportNotReady = errors.New("export is not ready yet")
+	ErrExportExpired  = errors.New("export link has expired")
 
 	//submission
 	ErrSubmissionNotFound = errors.New("submission not found")
+	ErrNoMaxPoints        = errors.New("submission was graded before points existed, override the score instead")
+
+	//organization
+	ErrOrgNotFound    = errors.New("organization not found")
+	ErrNotOrgMember   = errors.New("you are not a member of this organization")
+	ErrOrgSlugTaken   = errors.New("organization slug already taken")
+	ErrInvalidOrg     = errors.New("organization name must be 1-100 characters and slug 3-50 lowercase letters, numbers or -")
+	ErrInvalidOrgRole = errors.New("invalid organization role")
+	ErrAlreadyMember  = errors.New("user is already a member of this organization")
+	ErrLastOwner      = errors.New("organization must keep at least one owner")
+
+	//class
+	ErrClassNotFound    = errors.New("class not found")
+	ErrInvalidClassName = errors.New("class name must be 1-100 characters")
+	ErrInvalidJoinCode  = errors.New("invalid join code")
+	ErrAlreadyInClass   = errors.New("user is already in this class")
+	ErrNotInClass       = errors.New("user is not in this class")
+	ErrAmbiguousClass   = errors.New("class name matches more than one class, use the class id")
+	ErrJoinCodeRequired = errors.New("students outside an organization join the class with its join code")
+
+	//roster
+	ErrInvalidCSV    = errors.New("invalid csv file")
+	ErrEmptyRoster   = errors.New("csv has no rows")
+	ErrTooManyRows   = errors.New("csv can have at most 1000 rows")
+	ErrIncompleteRow = errors.New("row needs an email and a username")
+	ErrDuplicateRow  = errors.New("email or username is repeated in the file")
 )
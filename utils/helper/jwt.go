@@ -2,55 +2,133 @@ package helper
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwt_secret = []byte(os.Getenv("JWT_SECRET"))
+// set once at startup by SetKeyManager, after the env is loaded
+var signingKeys *KeyManager
+
+// iat carries milliseconds so a token issued in the same second as a revoke all is still caught
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
+
+func SetKeyManager(m *KeyManager) {
+	signingKeys = m
+}
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+	ChallengeTTL    = 5 * time.Minute
+	GuestTokenTTL   = 30 * 24 * time.Hour
+)
+
+// tokens with a purpose are not access tokens and must be rejected by the auth middleware
+const (
+	PurposeTwoFactor = "2fa_challenge"
+	PurposeGuest     = "guest"
+)
 
 type JWTClaims struct {
 	UserID     uint   `json:"user_id"`
 	Email      string `json:"email"`
+	Role       string `json:"role"`
 	IsVerified bool   `json:"is_verified"`
+	Purpose    string `json:"purpose,omitempty"`
+	SessionID  uint   `json:"sid,omitempty"`
+	OrgID      uint   `json:"org,omitempty"`
 	jwt.RegisteredClaims
+
+	// loaded from the membership on every request so role changes apply right away
+	OrgRole string `json:"-"`
+
+	// only set when the request was authenticated with an api key
+	APIKeyID uint     `json:"-"`
+	Scopes   []string `json:"-"`
 }
 
-func GenerateJWTRegister(userid uint, email string) (string, error) {
+func GenerateJWTLogin(userid uint, email, role string, verified bool, sessionId, orgId uint) (string, error) {
+	jti, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
 	claims := JWTClaims{
 		UserID:     userid,
 		Email:      email,
-		IsVerified: false,
+		Role:       role,
+		IsVerified: verified,
+		SessionID:  sessionId,
+		OrgID:      orgId,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwt_secret)
+	return signJWT(claims)
 }
 
-func GenerateJWTLogin(userid uint, email string, verified bool) (string, error) {
+// GenerateJWTChallenge issues the short lived token a 2fa user trades for real tokens after the totp step.
+func GenerateJWTChallenge(userid uint) (string, error) {
+	jti, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
 	claims := JWTClaims{
-		UserID:     userid,
-		Email:      email,
-		IsVerified: verified,
+		UserID:  userid,
+		Purpose: PurposeTwoFactor,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ChallengeTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwt_secret)
+	return signJWT(claims)
+}
+
+// GenerateJWTGuest issues the token an anonymous player keeps their attempts under, the subject is the guest id.
+func GenerateJWTGuest(guestId string) (string, error) {
+	jti, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	claims := JWTClaims{
+		Purpose: PurposeGuest,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   guestId,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(GuestTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return signJWT(claims)
 }
 
 func ParseJWT(tokenstring string) (*JWTClaims, error) {
+	if signingKeys == nil {
+		return nil, errors.New("jwt keys are not initialized")
+	}
+
 	token, err := jwt.ParseWithClaims(tokenstring, &JWTClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return jwt_secret, nil
-	})
+		kid, _ := t.Header["kid"].(string)
+		key, ok := signingKeys.Lookup(kid)
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if t.Method.Alg() != key.Alg {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.verifyKey(), nil
+	}, jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA, AlgHS256}))
 
 	if err != nil {
 		return nil, err
@@ -63,3 +141,18 @@ func ParseJWT(tokenstring string) (*JWTClaims, error) {
 
 	return claims, nil
 }
+
+func signJWT(claims JWTClaims) (string, error) {
+	if signingKeys == nil {
+		return "", errors.New("jwt keys are not initialized")
+	}
+
+	key, err := signingKeys.Current()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Alg), claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.signingKey())
+}
@@ -0,0 +1,335 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+	AlgHS256 = "HS256"
+
+	kidTimeLayout   = "20060102T150405"
+	minSecretLength = 32
+)
+
+// a new key is only used for signing after it has been in the jwks this long,
+// so services that cache the jwks already know it when the first token shows up
+const publishAhead = time.Hour
+
+type SigningKey struct {
+	Kid       string
+	Alg       string
+	CreatedAt time.Time
+	private   crypto.Signer
+	secret    []byte
+}
+
+func (k *SigningKey) signingKey() any {
+	if k.secret != nil {
+		return k.secret
+	}
+	return k.private
+}
+
+func (k *SigningKey) verifyKey() any {
+	if k.secret != nil {
+		return k.secret
+	}
+	return k.private.Public()
+}
+
+// KeyManager holds every key that can still verify tokens. Keys live as pem files in a
+// directory shared by all instances, the newest published key signs.
+type KeyManager struct {
+	mu       sync.RWMutex
+	alg      string
+	dir      string
+	rotation time.Duration
+	keys     []*SigningKey
+}
+
+// NewKeyManagerFromEnv reads JWT_ALG (RS256, EdDSA or HS256), JWT_KEYS_DIR and JWT_KEY_ROTATION.
+// HS256 refuses an empty or short JWT_SECRET instead of silently signing with it.
+func NewKeyManagerFromEnv() (*KeyManager, error) {
+	alg := os.Getenv("JWT_ALG")
+	if alg == "" {
+		alg = AlgRS256
+	}
+
+	if alg == AlgHS256 {
+		secret := os.Getenv("JWT_SECRET")
+		if err := checkSecret(secret); err != nil {
+			return nil, err
+		}
+		return &KeyManager{
+			alg:  alg,
+			keys: []*SigningKey{{Kid: "hs256", Alg: AlgHS256, secret: []byte(secret)}},
+		}, nil
+	}
+	if alg != AlgRS256 && alg != AlgEdDSA {
+		return nil, fmt.Errorf("unsupported JWT_ALG %q", alg)
+	}
+
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		dir = "keys"
+	}
+
+	rotation := 30 * 24 * time.Hour
+	if raw := os.Getenv("JWT_KEY_ROTATION"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 2*publishAhead {
+			return nil, fmt.Errorf("invalid JWT_KEY_ROTATION %q, must be at least %s", raw, 2*publishAhead)
+		}
+		rotation = d
+	}
+
+	m := &KeyManager{alg: alg, dir: dir, rotation: rotation}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := m.Refresh(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func checkSecret(secret string) error {
+	if len(secret) < minSecretLength {
+		return fmt.Errorf("JWT_SECRET must be at least %d characters for HS256", minSecretLength)
+	}
+	if strings.Count(secret, secret[:1]) == len(secret) {
+		return errors.New("JWT_SECRET is too weak")
+	}
+	return nil
+}
+
+func (m *KeyManager) Alg() string {
+	return m.alg
+}
+
+// Current is the key new tokens are signed with.
+func (m *KeyManager) Current() (*SigningKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var newest *SigningKey
+	for _, k := range m.keys {
+		if k.Alg != m.alg {
+			continue
+		}
+		if newest == nil {
+			newest = k
+		}
+		if k.secret != nil || time.Since(k.CreatedAt) >= publishAhead {
+			return k, nil
+		}
+	}
+
+	// fresh install, nothing has been published long enough yet
+	if newest != nil {
+		return newest, nil
+	}
+	return nil, errors.New("no signing key available")
+}
+
+func (m *KeyManager) Lookup(kid string) (*SigningKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, k := range m.keys {
+		if k.Kid == kid {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// JWKS publishes the public half of every key, symmetric keys are never published.
+func (m *KeyManager) JWKS() JWKS {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JWKS{Keys: []JWK{}}
+	for _, k := range m.keys {
+		if k.secret != nil {
+			continue
+		}
+		jwk, err := NewJWK(k.Kid, k.Alg, k.private.Public())
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// Refresh reloads the key directory, creates the next key when the newest one is due for
+// rotation and deletes keys that nothing can still be signed with.
+func (m *KeyManager) Refresh() error {
+	if m.dir == "" {
+		return nil
+	}
+
+	keys, err := loadKeys(m.dir)
+	if err != nil {
+		return err
+	}
+
+	var newest *SigningKey
+	for _, k := range keys {
+		if k.Alg == m.alg {
+			newest = k
+			break
+		}
+	}
+	if newest == nil || time.Since(newest.CreatedAt) >= m.rotation {
+		key, err := m.generate()
+		if err != nil {
+			return err
+		}
+		keys = append([]*SigningKey{key}, keys...)
+	}
+
+	keys = m.prune(keys)
+
+	m.mu.Lock()
+	m.keys = keys
+	m.mu.Unlock()
+	return nil
+}
+
+// StartRotation refreshes the keys on an interval until stop is closed.
+func (m *KeyManager) StartRotation(interval time.Duration, stop <-chan struct{}) {
+	if m.dir == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.Refresh(); err != nil {
+				log.Printf("failed refresh jwt keys %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// prune keeps every key younger than two rotations, the one before the newest key is still
+// signing during publishAhead and its tokens live far shorter than a rotation.
+func (m *KeyManager) prune(keys []*SigningKey) []*SigningKey {
+	kept := keys[:0]
+	for i, k := range keys {
+		if i > 0 && time.Since(k.CreatedAt) > 2*m.rotation {
+			if err := os.Remove(filepath.Join(m.dir, k.Kid+".pem")); err != nil && !os.IsNotExist(err) {
+				log.Printf("failed remove old jwt key %s %v", k.Kid, err)
+			}
+			continue
+		}
+		kept = append(kept, k)
+	}
+	return kept
+}
+
+func (m *KeyManager) generate() (*SigningKey, error) {
+	var private crypto.Signer
+	switch m.alg {
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	kid := now.Format(kidTimeLayout) + "-" + hex.EncodeToString(suffix)
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(m.dir, kid+".pem"), data, 0o600); err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{Kid: kid, Alg: m.alg, CreatedAt: now, private: private}, nil
+}
+
+// loadKeys returns the keys in the directory newest first, the kid starts with the creation time.
+func loadKeys(dir string) ([]*SigningKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*SigningKey
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		createdAt, err := time.Parse(kidTimeLayout, strings.SplitN(kid, "-", 2)[0])
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s has no timestamp in its name", file)
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("jwt key %s is not pem", file)
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", file, err)
+		}
+
+		key := &SigningKey{Kid: kid, CreatedAt: createdAt}
+		switch k := parsed.(type) {
+		case *rsa.PrivateKey:
+			key.Alg, key.private = AlgRS256, k
+		case ed25519.PrivateKey:
+			key.Alg, key.private = AlgEdDSA, k
+		default:
+			return nil, fmt.Errorf("jwt key %s has unsupported type %T", file, parsed)
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
@@ -0,0 +1,98 @@
+package helper
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PasswordPolicy is checked whenever a user picks a password, existing passwords are not re-validated.
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+	banned    map[string]struct{}
+}
+
+// NewPasswordPolicyFromEnv reads PASSWORD_MIN_LENGTH, PASSWORD_MAX_LENGTH and PASSWORD_BLOCKLIST,
+// a file with one common password per line.
+func NewPasswordPolicyFromEnv() (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{MinLength: 8, MaxLength: 128}
+
+	if raw := os.Getenv("PASSWORD_MIN_LENGTH"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 6 {
+			return nil, fmt.Errorf("invalid PASSWORD_MIN_LENGTH %q, must be at least 6", raw)
+		}
+		policy.MinLength = n
+	}
+	if raw := os.Getenv("PASSWORD_MAX_LENGTH"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < policy.MinLength || n > 1024 {
+			return nil, fmt.Errorf("invalid PASSWORD_MAX_LENGTH %q", raw)
+		}
+		policy.MaxLength = n
+	}
+
+	path := os.Getenv("PASSWORD_BLOCKLIST")
+	if path == "" {
+		path = "config/common-passwords.txt"
+	}
+	banned, err := loadBlocklist(path)
+	if err != nil {
+		return nil, err
+	}
+	policy.banned = banned
+
+	return policy, nil
+}
+
+func loadBlocklist(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed open password blocklist: %w", err)
+	}
+	defer file.Close()
+
+	banned := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		banned[line] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return banned, nil
+}
+
+// Check validates a new password against the policy and the account it is for.
+func (p *PasswordPolicy) Check(password, email, username string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return ErrPasswordTooShort
+	}
+	if length > p.MaxLength {
+		return ErrPasswordTooLong
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := p.banned[lower]; ok {
+		return ErrPasswordTooCommon
+	}
+
+	localPart, _, _ := strings.Cut(strings.ToLower(email), "@")
+	for _, personal := range []string{localPart, strings.ToLower(username)} {
+		if len(personal) >= 3 && strings.Contains(lower, personal) {
+			return ErrPasswordPersonal
+		}
+	}
+
+	return nil
+}
@@ -1,9 +1,51 @@
 package helper
 
-import "regexp"
+import (
+	"net/url"
+	"regexp"
+	"time"
+	_ "time/tzdata"
+)
+
+var (
+	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_.]{3,50}$`)
+	localeRegex   = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+	slugRegex     = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+)
 
 func IsValidEmail(email string) bool {
 	regex := `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
 	re := regexp.MustCompile(regex)
 	return re.MatchString(email)
 }
+
+func IsValidUsername(username string) bool {
+	return usernameRegex.MatchString(username)
+}
+
+// IsValidLocale accepts language tags like "id" or "en-US".
+func IsValidLocale(locale string) bool {
+	return localeRegex.MatchString(locale)
+}
+
+// IsValidTimeZone accepts iana names like "Asia/Jakarta", tzdata is embedded so it works without system zoneinfo.
+func IsValidTimeZone(tz string) bool {
+	if tz == "" || tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+// IsValidSlug accepts 3-50 lowercase letters and numbers separated by single dashes.
+func IsValidSlug(slug string) bool {
+	return len(slug) >= 3 && len(slug) <= 50 && slugRegex.MatchString(slug)
+}
+
+func IsValidHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// fileMailer drops every message as an .eml file, handy for local development.
+type fileMailer struct {
+	dir   string
+	from  string
+	count atomic.Uint64
+}
+
+func NewFileMailer(dir, from string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+func (m *fileMailer) Send(msg Message) error {
+	message := gomail.NewMessage()
+	message.SetHeader("From", m.from)
+	message.SetHeader("To", msg.To)
+	message.SetHeader("Subject", msg.Subject)
+	message.SetBody("text/plain", msg.Text)
+	message.AddAlternative("text/html", msg.HTML)
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s_%d_%s.eml", time.Now().Format("20060102T150405"), m.count.Add(1), recipient)
+
+	file, err := os.Create(filepath.Join(m.dir, name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = message.WriteTo(file)
+	return err
+}
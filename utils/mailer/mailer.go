@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// NewFromEnv picks the backend from MAIL_DRIVER (smtp, file or memory), defaulting to smtp.
+func NewFromEnv() (Mailer, error) {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = os.Getenv("EMAIL_SENDER")
+	}
+
+	switch os.Getenv("MAIL_DRIVER") {
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail_out"
+		}
+		return NewFileMailer(dir, from)
+	case "memory":
+		return NewMemoryMailer(), nil
+	case "", "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			host = "smtp.gmail.com"
+		}
+		port := 587
+		if p := os.Getenv("SMTP_PORT"); p != "" {
+			parsed, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+			}
+			port = parsed
+		}
+		return NewSMTPMailer(host, port, os.Getenv("EMAIL_SENDER"), os.Getenv("APP_PASSWORD"), from), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", os.Getenv("MAIL_DRIVER"))
+	}
+}
@@ -0,0 +1,26 @@
+package mailer
+
+import "gopkg.in/gomail.v2"
+
+type smtpMailer struct {
+	dialer *gomail.Dialer
+	from   string
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) Mailer {
+	return &smtpMailer{
+		dialer: gomail.NewDialer(host, port, username, password),
+		from:   from,
+	}
+}
+
+func (m *smtpMailer) Send(msg Message) error {
+	message := gomail.NewMessage()
+	message.SetHeader("From", m.from)
+	message.SetHeader("To", msg.To)
+	message.SetHeader("Subject", msg.Subject)
+	message.SetBody("text/plain", msg.Text)
+	message.AddAlternative("text/html", msg.HTML)
+
+	return m.dialer.DialAndSend(message)
+}
@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+)
+
+const (
+	TemplateVerification  = "verification"
+	TemplateResetPassword = "reset_password"
+	TemplateNotification  = "notification"
+	TemplateInvitation    = "invitation"
+)
+
+// Render builds a message from the html and text variants of the named template.
+func Render(to, subject, name string, data any) (Message, error) {
+	var html, text bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return Message{}, err
+	}
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: subject,
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
@@ -1,25 +1,53 @@
 package middleware
 
 import (
+	"api_quiz/internal/repository"
 	"api_quiz/utils/helper"
 	"context"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type key int
 
 const UserContextKey key = 0
 
-func JWTAuthMiddleware(next http.Handler) http.Handler {
+// last_used_at and last_seen_at are only written once per interval so every api call is not a db write
+const (
+	apiKeyTouchInterval  = time.Minute
+	sessionTouchInterval = time.Minute
+)
+
+type AuthMiddleware struct {
+	revocationRepo repository.RevocationRepository
+	apiKeyRepo     repository.APIKeyRepository
+	sessionRepo    repository.SessionRepository
+	orgRepo        repository.OrganizationRepository
+}
+
+func NewAuthMiddleware(revocationRepo repository.RevocationRepository, apiKeyRepo repository.APIKeyRepository, sessionRepo repository.SessionRepository, orgRepo repository.OrganizationRepository) *AuthMiddleware {
+	return &AuthMiddleware{revocationRepo, apiKeyRepo, sessionRepo, orgRepo}
+}
+
+// JWTAuthMiddleware accepts a Bearer jwt, or a personal api key in the Bearer or X-API-Key header.
+func (m *AuthMiddleware) JWTAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
+		if authHeader == "" && r.Header.Get("X-API-Key") == "" {
 			http.Error(w, "Unauthorized: No token provided", http.StatusUnauthorized)
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+			tokenString = apiKey
+		}
+		if helper.IsAPIKey(tokenString) {
+			m.apiKeyAuth(w, r, next, tokenString)
+			return
+		}
+
 		claims, err := helper.ParseJWT(tokenString)
 		if err != nil || !claims.IsVerified {
 			http.Error(w, "Unauthorized: Invalid or unverified user", http.StatusForbidden)
@@ -31,7 +59,142 @@ func JWTAuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		if claims.Purpose != "" {
+			http.Error(w, "Unauthorized: not an access token", http.StatusUnauthorized)
+			return
+		}
+
+		revoked, err := m.isRevoked(r, claims)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			http.Error(w, "Unauthorized: token has been revoked", http.StatusUnauthorized)
+			return
+		}
+
+		if !m.loadOrgRole(w, claims) {
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), UserContextKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// loadOrgRole checks the caller still belongs to the organization in the token and fills in the role.
+// The role is read on every request so promotions and removals apply right away.
+func (m *AuthMiddleware) loadOrgRole(w http.ResponseWriter, claims *helper.JWTClaims) bool {
+	if claims.OrgID == 0 {
+		return true
+	}
+
+	member, err := m.orgRepo.GetMembership(claims.OrgID, claims.UserID)
+	if err != nil {
+		if err == helper.ErrNotOrgMember {
+			http.Error(w, "Forbidden: not a member of this organization", http.StatusForbidden)
+			return false
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return false
+	}
+
+	claims.OrgRole = member.Role
+	return true
+}
+
+func (m *AuthMiddleware) isRevoked(r *http.Request, claims *helper.JWTClaims) (bool, error) {
+	if claims.ID == "" || claims.IssuedAt == nil {
+		return true, nil
+	}
+
+	revoked, err := m.revocationRepo.IsTokenRevoked(claims.ID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	revoked, err = m.revocationRepo.IsUserRevoked(claims.UserID, claims.IssuedAt.Time)
+	if err != nil || revoked || claims.SessionID == 0 {
+		return revoked, err
+	}
+
+	return m.isSessionRevoked(r, claims)
+}
+
+// isSessionRevoked kills access tokens of a revoked session right away instead of at expiry.
+func (m *AuthMiddleware) isSessionRevoked(r *http.Request, claims *helper.JWTClaims) (bool, error) {
+	session, err := m.sessionRepo.GetSessionById(claims.SessionID)
+	if err != nil {
+		if err == helper.ErrSessionNotFound {
+			return true, nil
+		}
+		return false, err
+	}
+	if session.RevokedAt != nil || session.UserID != claims.UserID {
+		return true, nil
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		if err := m.sessionRepo.TouchSession(session.ID, helper.ClientIP(r), r.UserAgent(), now); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+func (m *AuthMiddleware) apiKeyAuth(w http.ResponseWriter, r *http.Request, next http.Handler, apiKey string) {
+	key, err := m.apiKeyRepo.GetAPIKeyByHash(helper.HashToken(apiKey))
+	if err != nil {
+		if err == helper.ErrAPIKeyNotFound {
+			http.Error(w, "Unauthorized: invalid api key", http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// the owner is not preloaded when the account was deleted
+	if key.User.ID == 0 {
+		http.Error(w, "Unauthorized: invalid api key", http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		http.Error(w, "Unauthorized: api key has been revoked or expired", http.StatusUnauthorized)
+		return
+	}
+	if !key.User.IsVerified {
+		http.Error(w, "Unauthorized: user is not verified", http.StatusForbidden)
+		return
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := m.apiKeyRepo.TouchAPIKey(key.ID, now); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// role and verification come from the user row so changes apply to keys right away
+	claims := &helper.JWTClaims{
+		UserID:     key.User.ID,
+		Email:      key.User.Email,
+		Role:       key.User.Role,
+		IsVerified: key.User.IsVerified,
+		APIKeyID:   key.ID,
+		Scopes:     strings.Fields(key.Scopes),
+	}
+	if key.OrganizationID != nil {
+		claims.OrgID = *key.OrganizationID
+	}
+	if !m.loadOrgRole(w, claims) {
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserContextKey, claims)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
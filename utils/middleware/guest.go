@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"api_quiz/utils/helper"
+	"context"
+	"net/http"
+	"strings"
+)
+
+const GuestContextKey key = 1
+
+// GuestAuthMiddleware accepts a guest token in the X-Guest-Token header or as Bearer.
+// The guest claims are stored under GuestContextKey, the subject is the guest id.
+func (m *AuthMiddleware) GuestAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenString := r.Header.Get("X-Guest-Token")
+		if tokenString == "" {
+			tokenString = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		if tokenString == "" {
+			http.Error(w, "Unauthorized: No guest token provided", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := helper.ParseJWT(tokenString)
+		if err != nil || claims.Purpose != helper.PurposeGuest || claims.Subject == "" || claims.ID == "" {
+			http.Error(w, "Unauthorized: invalid guest token", http.StatusUnauthorized)
+			return
+		}
+
+		// a claimed guest token is revoked so it can not keep adding attempts to nobody
+		revoked, err := m.revocationRepo.IsTokenRevoked(claims.ID)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			http.Error(w, "Unauthorized: guest token has been claimed", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), GuestContextKey, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"api_quiz/utils/helper"
+	"net/http"
+	"slices"
+
+	"github.com/gorilla/mux"
+)
+
+// RequireRole must run after JWTAuthMiddleware since it reads the claims from the context.
+func RequireRole(roles ...string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(UserContextKey).(*helper.JWTClaims)
+			if !ok {
+				helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+				return
+			}
+
+			if !slices.Contains(roles, claims.Role) {
+				helper.WriteError(w, http.StatusForbidden, helper.ErrForbidden.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
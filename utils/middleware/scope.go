@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"api_quiz/utils/helper"
+	"net/http"
+	"slices"
+
+	"github.com/gorilla/mux"
+)
+
+// RequireScope only restricts api keys that were created with scopes, jwt sessions always pass.
+func RequireScope(scope string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(UserContextKey).(*helper.JWTClaims)
+			if !ok {
+				helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+				return
+			}
+
+			if claims.APIKeyID != 0 && len(claims.Scopes) > 0 && !slices.Contains(claims.Scopes, scope) {
+				helper.WriteError(w, http.StatusForbidden, "api key is missing scope "+scope)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession keeps api keys away from account management like keys, 2fa and logout.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(UserContextKey).(*helper.JWTClaims)
+		if !ok {
+			helper.WriteError(w, http.StatusUnauthorized, "not token provide")
+			return
+		}
+
+		if claims.APIKeyID != 0 {
+			helper.WriteError(w, http.StatusForbidden, "api keys can not be used for this action")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,58 @@
+package oidc
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+var defaultIssuers = map[string]string{
+	"google":    "https://accounts.google.com",
+	"microsoft": "https://login.microsoftonline.com/common/v2.0",
+}
+
+// ProvidersFromEnv reads OIDC_PROVIDERS (comma separated names) and the
+// OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID and OIDC_<NAME>_CLIENT_SECRET of each provider.
+// OIDC_<NAME>_TENANTS (comma separated tenant ids) is required for multi tenant issuers like microsoft.
+func ProvidersFromEnv(baseURL string) map[string]*Provider {
+	providers := make(map[string]*Provider)
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		issuer := os.Getenv(prefix + "ISSUER")
+		if issuer == "" {
+			issuer = defaultIssuers[name]
+		}
+		clientId := os.Getenv(prefix + "CLIENT_ID")
+		if issuer == "" || clientId == "" {
+			log.Printf("⚠ oidc provider %s skipped, issuer or client id is missing", name)
+			continue
+		}
+
+		var tenants []string
+		for _, tenant := range strings.Split(os.Getenv(prefix+"TENANTS"), ",") {
+			if tenant = strings.TrimSpace(tenant); tenant != "" {
+				tenants = append(tenants, tenant)
+			}
+		}
+		if name == "microsoft" && len(tenants) == 0 {
+			log.Printf("⚠ oidc provider %s has no %sTENANTS, every sign in will be rejected", name, prefix)
+		}
+
+		providers[name] = NewProvider(Config{
+			Name:         name,
+			Issuer:       issuer,
+			ClientID:     clientId,
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  strings.TrimRight(baseURL, "/") + "/oauth/" + name + "/callback",
+			Tenants:      tenants,
+		}, nil)
+	}
+
+	return providers
+}